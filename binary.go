@@ -0,0 +1,543 @@
+package doric
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+const errorMalformedBinary = "Malformed binary data"
+
+// MarshalBinary implements encoding.BinaryMarshaler. Wells are written as the encoding version,
+// their dimensions and every tile in 4 bits, column by column.
+func (p Well) MarshalBinary() ([]byte, error) {
+	e := newEncoder()
+	e.well(p)
+	return e.bytes()
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func (p *Well) UnmarshalBinary(data []byte) error {
+	d, err := newDecoder(data)
+	if err != nil {
+		return err
+	}
+	well := d.well()
+	if err := d.close(); err != nil {
+		return err
+	}
+	*p = well
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (p Column) MarshalBinary() ([]byte, error) {
+	e := newEncoder()
+	e.column(p)
+	return e.bytes()
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func (p *Column) UnmarshalBinary(data []byte) error {
+	d, err := newDecoder(data)
+	if err != nil {
+		return err
+	}
+	column := d.column()
+	if err := d.close(); err != nil {
+		return err
+	}
+	*p = column
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (s GameState) MarshalBinary() ([]byte, error) {
+	e := newEncoder()
+	e.state(s)
+	return e.bytes()
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func (s *GameState) UnmarshalBinary(data []byte) error {
+	d, err := newDecoder(data)
+	if err != nil {
+		return err
+	}
+	state := d.state()
+	if err := d.close(); err != nil {
+		return err
+	}
+	*s = state
+	return nil
+}
+
+// MarshalEventBinary returns the passed event in a compact binary format, made of the encoding version,
+// the event kind, its header and its fields in order. As with JSON, only the message of errors is written.
+func MarshalEventBinary(ev Event) ([]byte, error) {
+	e := newEncoder()
+	e.uint(uint64(ev.Kind()))
+	e.uint(ev.Sequence())
+	e.int(ev.GameTick())
+	switch ev := ev.(type) {
+	case EventUpdated:
+		e.column(ev.Column)
+		e.int(ev.LandingY)
+	case EventScored:
+		e.well(ev.Well)
+		e.int(ev.Combo)
+		e.int(ev.Removed)
+		e.matches(ev.Matches)
+		e.int(ev.Level)
+		e.int(ev.Points)
+		e.int(ev.Score)
+	case EventRenewed:
+		e.well(ev.Well)
+		e.column(ev.Column)
+		e.ints(ev.NextTileset)
+		e.int(ev.LandingY)
+		e.int(ev.Score)
+	case EventMagicJewel:
+		e.well(ev.Well)
+		e.int(ev.Color)
+		e.int(ev.Removed)
+		e.int(ev.Level)
+		e.int(ev.Points)
+		e.int(ev.Score)
+	case EventGameOver:
+		e.int(int(ev.Reason))
+		e.well(ev.Well)
+		e.int(ev.Level)
+		e.int(ev.Score)
+		e.int(ev.TotalRemoved)
+		e.int(ev.MaxCombo)
+		e.int(int(ev.Elapsed))
+		msg := ""
+		if ev.Err != nil {
+			msg = ev.Err.Error()
+		}
+		e.string(msg)
+		e.uint(ev.Dropped)
+	case EventSnapshot:
+		e.state(ev.State)
+	case EventRejected:
+		e.int(int(ev.Command))
+		e.int(int(ev.Reason))
+	case EventTouchedDown:
+		e.column(ev.Column)
+		e.int(ev.LockDelay)
+	case EventClearStarted:
+		e.int(ev.Combo)
+		e.int(ev.Delay)
+	case EventSettled:
+		e.well(ev.Well)
+		e.moves(ev.Moves)
+	default:
+		return nil, fmt.Errorf(errorUnknownEventKind, ev.Kind().String())
+	}
+	return e.bytes()
+}
+
+// UnmarshalEventBinary returns the event written in the passed data by MarshalEventBinary.
+// The returned event has the same concrete type as the original one, e. g. EventScored.
+func UnmarshalEventBinary(data []byte) (Event, error) {
+	d, err := newDecoder(data)
+	if err != nil {
+		return nil, err
+	}
+	kind := EventKind(d.uint())
+	header := EventHeader{Seq: d.uint(), Tick: d.int()}
+	var ev Event
+	switch kind {
+	case KindUpdated:
+		ev = EventUpdated{EventHeader: header, Column: d.column(), LandingY: d.int()}
+	case KindScored:
+		ev = EventScored{EventHeader: header, Well: d.well(), Combo: d.int(), Removed: d.int(), Matches: d.matches(),
+			Level: d.int(), Points: d.int(), Score: d.int()}
+	case KindRenewed:
+		ev = EventRenewed{EventHeader: header, Well: d.well(), Column: d.column(), NextTileset: d.ints(),
+			LandingY: d.int(), Score: d.int()}
+	case KindMagicJewel:
+		ev = EventMagicJewel{EventHeader: header, Well: d.well(), Color: d.int(), Removed: d.int(), Level: d.int(),
+			Points: d.int(), Score: d.int()}
+	case KindGameOver:
+		g := EventGameOver{EventHeader: header, Reason: GameOverReason(d.int()), Well: d.well(), Level: d.int(),
+			Score: d.int(), TotalRemoved: d.int(), MaxCombo: d.int(), Elapsed: time.Duration(d.int())}
+		if msg := d.string(); msg != "" {
+			g.Err = errors.New(msg)
+		}
+		g.Dropped = d.uint()
+		ev = g
+	case KindSnapshot:
+		ev = EventSnapshot{EventHeader: header, State: d.state()}
+	case KindRejected:
+		ev = EventRejected{EventHeader: header, Command: Command(d.int()), Reason: RejectionReason(d.int())}
+	case KindTouchedDown:
+		ev = EventTouchedDown{EventHeader: header, Column: d.column(), LockDelay: d.int()}
+	case KindClearStarted:
+		ev = EventClearStarted{EventHeader: header, Combo: d.int(), Delay: d.int()}
+	case KindSettled:
+		ev = EventSettled{EventHeader: header, Well: d.well(), Moves: d.moves()}
+	default:
+		if d.err == nil {
+			return nil, fmt.Errorf(errorUnknownEventKind, kind.String())
+		}
+	}
+	if err := d.close(); err != nil {
+		return nil, err
+	}
+	return ev, nil
+}
+
+// encoder writes values in binary format, starting with the encoding version.
+// Integers are written as varints.
+type encoder struct {
+	buf bytes.Buffer
+	err error
+}
+
+func newEncoder() *encoder {
+	e := &encoder{}
+	e.uint(EncodingVersion)
+	return e
+}
+
+func (e *encoder) bytes() ([]byte, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.buf.Bytes(), nil
+}
+
+func (e *encoder) int(v int) {
+	var b [binary.MaxVarintLen64]byte
+	e.buf.Write(b[:binary.PutVarint(b[:], int64(v))])
+}
+
+func (e *encoder) uint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	e.buf.Write(b[:binary.PutUvarint(b[:], v)])
+}
+
+func (e *encoder) bool(v bool) {
+	if v {
+		e.buf.WriteByte(1)
+		return
+	}
+	e.buf.WriteByte(0)
+}
+
+func (e *encoder) float(v float64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], math.Float64bits(v))
+	e.buf.Write(b[:])
+}
+
+func (e *encoder) string(v string) {
+	e.uint(uint64(len(v)))
+	e.buf.WriteString(v)
+}
+
+func (e *encoder) ints(v []int) {
+	e.uint(uint64(len(v)))
+	for _, n := range v {
+		e.int(n)
+	}
+}
+
+// well writes the dimensions of the well and then its tiles, two per byte.
+// Tiles are increased by one so Remove fits in 4 bits.
+func (e *encoder) well(p Well) {
+	for x := range p {
+		if len(p[x]) != p.Height() {
+			e.err = ErrWellNotRectangular
+			return
+		}
+	}
+	e.uint(uint64(p.Width()))
+	e.uint(uint64(p.Height()))
+	var b byte
+	i := 0
+	for x := range p {
+		for y := range p[x] {
+			tile := p[x][y]
+			if tile < Remove || tile > MagicJewel {
+				e.err = fmt.Errorf(errorUnprintableTile)
+				return
+			}
+			if i%2 == 0 {
+				b = byte(tile+1) << 4
+			} else {
+				e.buf.WriteByte(b | byte(tile+1))
+			}
+			i++
+		}
+	}
+	if i%2 == 1 {
+		e.buf.WriteByte(b)
+	}
+}
+
+func (e *encoder) column(c Column) {
+	e.ints(c.Tileset)
+	e.int(c.X)
+	e.int(c.Y)
+}
+
+func (e *encoder) position(p Position) {
+	e.int(p.X)
+	e.int(p.Y)
+}
+
+func (e *encoder) matches(v []Match) {
+	e.uint(uint64(len(v)))
+	for _, m := range v {
+		e.int(int(m.Direction))
+		e.int(m.Length)
+		e.int(m.Color)
+		e.uint(uint64(len(m.Cells)))
+		for _, p := range m.Cells {
+			e.position(p)
+		}
+	}
+}
+
+func (e *encoder) moves(v []Move) {
+	e.uint(uint64(len(v)))
+	for _, m := range v {
+		e.position(m.From)
+		e.position(m.To)
+		e.int(m.Tile)
+	}
+}
+
+func (e *encoder) state(s GameState) {
+	e.well(s.Well)
+	e.column(s.Column)
+	e.ints(s.NextTileset)
+	e.int(s.Level)
+	e.float(s.Speed)
+	e.int(s.TotalRemoved)
+	e.int(s.MaxCombo)
+	e.int(s.Score)
+	e.int(s.Dropped)
+	e.bool(s.TouchedDown)
+	e.int(s.LockTicks)
+	e.int(s.LockResets)
+	e.bool(s.Clearing)
+	e.bool(s.Spawning)
+	e.int(s.Delay)
+	e.int(s.Combo)
+	e.int(s.Drawn)
+	e.int(s.StartLevel)
+}
+
+// decoder reads values written by encoder. Once an error happens, it is kept and
+// all following reads return zero values.
+type decoder struct {
+	r   *bytes.Reader
+	err error
+}
+
+// newDecoder returns a decoder for the passed data, or an error if it was written
+// with an unsupported encoding version
+func newDecoder(data []byte) (*decoder, error) {
+	d := &decoder{r: bytes.NewReader(data)}
+	version := d.uint()
+	if d.err != nil {
+		return nil, d.err
+	}
+	if version != EncodingVersion {
+		return nil, fmt.Errorf(errorUnsupportedEncodingVersion, version)
+	}
+	return d, nil
+}
+
+// close returns the first error found while decoding, or an error if not all data was read
+func (d *decoder) close() error {
+	if d.err == nil && d.r.Len() > 0 {
+		d.err = fmt.Errorf(errorMalformedBinary)
+	}
+	return d.err
+}
+
+func (d *decoder) fail(err error) {
+	if d.err != nil {
+		return
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = fmt.Errorf(errorMalformedBinary)
+	}
+	d.err = err
+}
+
+func (d *decoder) int() int {
+	if d.err != nil {
+		return 0
+	}
+	v, err := binary.ReadVarint(d.r)
+	d.fail(err)
+	return int(v)
+}
+
+func (d *decoder) uint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(d.r)
+	d.fail(err)
+	return v
+}
+
+// len reads the length of a slice, failing if there are not enough bytes left
+// to read as many elements of the passed minimum size
+func (d *decoder) len(size int) int {
+	n := d.uint()
+	if n > uint64(d.r.Len()/size) {
+		d.fail(fmt.Errorf(errorMalformedBinary))
+		return 0
+	}
+	return int(n)
+}
+
+func (d *decoder) bool() bool {
+	if d.err != nil {
+		return false
+	}
+	b, err := d.r.ReadByte()
+	d.fail(err)
+	return b == 1
+}
+
+func (d *decoder) float() float64 {
+	if d.err != nil {
+		return 0
+	}
+	var b [8]byte
+	_, err := io.ReadFull(d.r, b[:])
+	d.fail(err)
+	return math.Float64frombits(binary.BigEndian.Uint64(b[:]))
+}
+
+func (d *decoder) string() string {
+	b := make([]byte, d.len(1))
+	if d.err != nil {
+		return ""
+	}
+	_, err := io.ReadFull(d.r, b)
+	d.fail(err)
+	return string(b)
+}
+
+func (d *decoder) ints() []int {
+	n := d.len(1)
+	if d.err != nil || n == 0 {
+		return nil
+	}
+	v := make([]int, n)
+	for i := range v {
+		v[i] = d.int()
+	}
+	return v
+}
+
+func (d *decoder) well() Well {
+	width := d.uint()
+	height := d.uint()
+	if d.err != nil {
+		return nil
+	}
+	left := uint64(d.r.Len()) * 2
+	if width > left || height > left || width*height > left {
+		d.fail(fmt.Errorf(errorMalformedBinary))
+		return nil
+	}
+	p := NewWell(int(width), int(height))
+	var b byte
+	i := 0
+	for x := range p {
+		for y := range p[x] {
+			var tile byte
+			if i%2 == 0 {
+				b, _ = d.r.ReadByte()
+				tile = b >> 4
+			} else {
+				tile = b & 0x0f
+			}
+			if int(tile)-1 > MagicJewel {
+				d.fail(fmt.Errorf(errorMalformedBinary))
+				return nil
+			}
+			p[x][y] = int(tile) - 1
+			i++
+		}
+	}
+	return p
+}
+
+func (d *decoder) column() Column {
+	return Column{Tileset: d.ints(), X: d.int(), Y: d.int()}
+}
+
+func (d *decoder) position() Position {
+	return Position{X: d.int(), Y: d.int()}
+}
+
+func (d *decoder) matches() []Match {
+	n := d.len(4)
+	if d.err != nil || n == 0 {
+		return nil
+	}
+	v := make([]Match, n)
+	for i := range v {
+		v[i] = Match{Direction: Direction(d.int()), Length: d.int(), Color: d.int()}
+		cells := d.len(2)
+		if d.err != nil {
+			return nil
+		}
+		v[i].Cells = make([]Position, cells)
+		for j := range v[i].Cells {
+			v[i].Cells[j] = d.position()
+		}
+	}
+	return v
+}
+
+func (d *decoder) moves() []Move {
+	n := d.len(5)
+	if d.err != nil || n == 0 {
+		return nil
+	}
+	v := make([]Move, n)
+	for i := range v {
+		v[i] = Move{From: d.position(), To: d.position(), Tile: d.int()}
+	}
+	return v
+}
+
+func (d *decoder) state() GameState {
+	return GameState{
+		Well:         d.well(),
+		Column:       d.column(),
+		NextTileset:  d.ints(),
+		Level:        d.int(),
+		Speed:        d.float(),
+		TotalRemoved: d.int(),
+		MaxCombo:     d.int(),
+		Score:        d.int(),
+		Dropped:      d.int(),
+		TouchedDown:  d.bool(),
+		LockTicks:    d.int(),
+		LockResets:   d.int(),
+		Clearing:     d.bool(),
+		Spawning:     d.bool(),
+		Delay:        d.int(),
+		Combo:        d.int(),
+		Drawn:        d.int(),
+		StartLevel:   d.int(),
+	}
+}
@@ -0,0 +1,67 @@
+package doric
+
+import "math/rand"
+
+// NewRandomBuilder returns a TilesetBuilder which picks every tile at random with a uniform distribution.
+// Builders created with the same seed return the same sequence of tilesets.
+func NewRandomBuilder(seed int64) TilesetBuilder {
+	r := rand.New(rand.NewSource(seed))
+	return func(numColors, length int) []int {
+		tileset := make([]int, length)
+		for i := range tileset {
+			tileset[i] = r.Intn(numColors) + 1
+		}
+		return tileset
+	}
+}
+
+// NewBagBuilder returns a TilesetBuilder which draws tiles from a shuffled bag containing one tile of each color,
+// refilling it when it is empty. This guarantees a balanced distribution of colors, avoiding long droughts
+// of any of them. Builders created with the same seed return the same sequence of tilesets.
+func NewBagBuilder(seed int64) TilesetBuilder {
+	r := rand.New(rand.NewSource(seed))
+	var bag []int
+	return func(numColors, length int) []int {
+		tileset := make([]int, length)
+		for i := range tileset {
+			if len(bag) == 0 {
+				bag = r.Perm(numColors)
+			}
+			tileset[i] = bag[0] + 1
+			bag = bag[1:]
+		}
+		return tileset
+	}
+}
+
+// NewSequenceBuilder returns a TilesetBuilder which returns the passed tilesets in order,
+// starting again from the first one once all of them were returned.
+// Number of colors and column length are ignored, so passed tilesets must be valid for the game configuration.
+// At least one tileset must be passed, or NewSequenceBuilder panics.
+func NewSequenceBuilder(tilesets ...[]int) TilesetBuilder {
+	if len(tilesets) == 0 {
+		panic("doric: NewSequenceBuilder needs at least one tileset")
+	}
+	current := 0
+	return func(numColors, length int) []int {
+		tileset := copyTileset(tilesets[current])
+		current = (current + 1) % len(tilesets)
+		return tileset
+	}
+}
+
+// WithMagicJewel wraps the passed builder, so that every new column has the passed probability
+// (between 0 and 1) of being a magic column. Random numbers are generated from the passed seed.
+func WithMagicJewel(builder TilesetBuilder, probability float64, seed int64) TilesetBuilder {
+	r := rand.New(rand.NewSource(seed))
+	return func(numColors, length int) []int {
+		if r.Float64() < probability {
+			tileset := make([]int, length)
+			for i := range tileset {
+				tileset[i] = MagicJewel
+			}
+			return tileset
+		}
+		return builder(numColors, length)
+	}
+}
@@ -0,0 +1,70 @@
+package doric_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/svera/doric"
+)
+
+func TestRandomBuilder(t *testing.T) {
+	first := doric.NewRandomBuilder(42)
+	second := doric.NewRandomBuilder(42)
+	for i := 0; i < 100; i++ {
+		a, b := first(4, 3), second(4, 3)
+		if !reflect.DeepEqual(a, b) {
+			t.Fatalf("Expected builders with the same seed to return the same tilesets, got %v and %v", a, b)
+		}
+		for _, tile := range a {
+			if tile < 1 || tile > 4 {
+				t.Fatalf("Expected tiles between 1 and 4, got %v", a)
+			}
+		}
+	}
+}
+
+func TestBagBuilder(t *testing.T) {
+	builder := doric.NewBagBuilder(42)
+	counts := map[int]int{}
+	// 20 tilesets of 3 tiles hold 10 full bags of 6 colors
+	for i := 0; i < 20; i++ {
+		for _, tile := range builder(6, 3) {
+			counts[tile]++
+		}
+	}
+	for color := 1; color <= 6; color++ {
+		if counts[color] != 10 {
+			t.Errorf("Expected color %d to appear 10 times, got %d", color, counts[color])
+		}
+	}
+}
+
+func TestSequenceBuilder(t *testing.T) {
+	builder := doric.NewSequenceBuilder([]int{1, 2, 3}, []int{4, 5, 6})
+	expected := [][]int{{1, 2, 3}, {4, 5, 6}, {1, 2, 3}}
+	for _, exp := range expected {
+		if ts := builder(6, 3); !reflect.DeepEqual(ts, exp) {
+			t.Errorf("Expected tileset %v but got %v", exp, ts)
+		}
+	}
+}
+
+func TestSequenceBuilderNoTilesets(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected panic when creating a sequence builder without tilesets")
+		}
+	}()
+	doric.NewSequenceBuilder()
+}
+
+func TestWithMagicJewel(t *testing.T) {
+	builder := doric.WithMagicJewel(doric.NewSequenceBuilder([]int{1, 2, 3}), 1, 0)
+	if ts := builder(6, 3); !reflect.DeepEqual(ts, []int{doric.MagicJewel, doric.MagicJewel, doric.MagicJewel}) {
+		t.Errorf("Expected a magic column, got %v", ts)
+	}
+	builder = doric.WithMagicJewel(doric.NewSequenceBuilder([]int{1, 2, 3}), 0, 0)
+	if ts := builder(6, 3); !reflect.DeepEqual(ts, []int{1, 2, 3}) {
+		t.Errorf("Expected a regular column, got %v", ts)
+	}
+}
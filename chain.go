@@ -0,0 +1,59 @@
+package doric
+
+// Directions in which tiles can be aligned to be removed
+const (
+	DirectionHorizontal Direction = iota
+	DirectionVertical
+	// Diagonal going down from left to right, like \
+	DirectionDiagonalDown
+	// Diagonal going up from left to right, like /
+	DirectionDiagonalUp
+)
+
+// Direction tells how the tiles of a match are aligned
+type Direction int
+
+func (d Direction) String() string {
+	switch d {
+	case DirectionHorizontal:
+		return "horizontal"
+	case DirectionVertical:
+		return "vertical"
+	case DirectionDiagonalDown:
+		return "diagonal down"
+	case DirectionDiagonalUp:
+		return "diagonal up"
+	}
+	return "unknown"
+}
+
+// Position holds the coordinates of a cell in the well
+type Position struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Match is a line of Length tiles of the same color removed in a step of a chain.
+// Cells are ordered from the first tile of the line, and may be shared with other matches
+// of the same step, e. g. when two lines cross.
+type Match struct {
+	Direction Direction  `json:"direction"`
+	Length    int        `json:"length"`
+	Color     int        `json:"color"`
+	Cells     []Position `json:"cells"`
+}
+
+// Move is a tile which fell from one cell to another after tiles under it were removed
+type Move struct {
+	From Position `json:"from"`
+	To   Position `json:"to"`
+	Tile int      `json:"tile"`
+}
+
+// ChainStep holds the tiles removed in a step of a chain and how the remaining ones fell afterwards.
+// Removed may be lower than the sum of the lengths of the matches, if some of them cross.
+type ChainStep struct {
+	Matches []Match `json:"matches"`
+	Removed int     `json:"removed"`
+	Moves   []Move  `json:"moves"`
+}
@@ -0,0 +1,159 @@
+package doric
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Possible errors returned when creating a clock
+const (
+	errorLessEqualZeroClockFactor = "ScaledClock factor must be greater than 0"
+)
+
+// Ticker delivers ticks at regular intervals through the channel returned by C.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Clock is the source of time used by Play to make columns fall.
+// Implementations other than the default wall clock can be used to run games
+// under simulated time, e. g. in tests or headless simulators.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// wallClock is the default Clock, backed by the time package
+type wallClock struct{}
+
+func (wallClock) Now() time.Time {
+	return time.Now()
+}
+
+func (wallClock) NewTicker(d time.Duration) Ticker {
+	return wallTicker{time.NewTicker(d)}
+}
+
+type wallTicker struct {
+	ticker *time.Ticker
+}
+
+func (t wallTicker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+func (t wallTicker) Stop() {
+	t.ticker.Stop()
+}
+
+// ScaledClock returns a Clock whose time runs factor times faster than wall-clock time, both in its tickers
+// and in Now, which starts at the current wall-clock time. A factor of 2, for example, makes columns fall
+// twice as fast as configured, and game time (e. g. EventGameOver.Elapsed) twice as long as the time played.
+// Returns an error if the factor is not greater than 0.
+func ScaledClock(factor float64) (Clock, error) {
+	if factor <= 0 {
+		return nil, fmt.Errorf(errorLessEqualZeroClockFactor)
+	}
+	return scaledClock{factor: factor, start: time.Now()}, nil
+}
+
+type scaledClock struct {
+	factor float64
+	start  time.Time
+}
+
+func (c scaledClock) Now() time.Time {
+	return c.start.Add(time.Duration(float64(time.Since(c.start)) * c.factor))
+}
+
+func (c scaledClock) NewTicker(d time.Duration) Ticker {
+	return wallTicker{time.NewTicker(time.Duration(float64(d) / c.factor))}
+}
+
+// ManualClock is a Clock whose tickers only fire when Tick is called,
+// so falls can be driven one at a time.
+type ManualClock struct {
+	mux     sync.Mutex
+	now     time.Time
+	c       chan time.Time
+	tickers int
+	// stopped is closed when the last running ticker is stopped
+	stopped chan struct{}
+}
+
+// NewManualClock returns a new ManualClock instance, whose current time is the one passed
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{
+		now:     now,
+		c:       make(chan time.Time),
+		stopped: make(chan struct{}),
+	}
+}
+
+// Now returns the current time of the clock
+func (m *ManualClock) Now() time.Time {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return m.now
+}
+
+// NewTicker returns a ticker which fires every time Tick is called.
+// The interval is ignored, all tickers created by the same clock share the same channel.
+func (m *ManualClock) NewTicker(d time.Duration) Ticker {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.tickers++
+	return &manualTicker{clock: m}
+}
+
+// Advance moves the current time of the clock forward without firing any tick
+func (m *ManualClock) Advance(d time.Duration) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.now = m.now.Add(d)
+}
+
+// Tick fires a tick, blocking until it is received. If there are no running tickers, e. g. because
+// the game using the clock is over, or they are all stopped while waiting, Tick returns without firing it,
+// so it is safe to keep calling it after the game ends.
+func (m *ManualClock) Tick() {
+	m.mux.Lock()
+	if m.tickers == 0 {
+		m.mux.Unlock()
+		return
+	}
+	now, stopped := m.now, m.stopped
+	m.mux.Unlock()
+
+	select {
+	case m.c <- now:
+	case <-stopped:
+	}
+}
+
+type manualTicker struct {
+	clock   *ManualClock
+	stopped bool
+}
+
+func (t *manualTicker) C() <-chan time.Time {
+	return t.clock.c
+}
+
+// Stop stops the ticker. Calling it more than once has no effect.
+func (t *manualTicker) Stop() {
+	m := t.clock
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if t.stopped {
+		return
+	}
+	t.stopped = true
+	m.tickers--
+	if m.tickers == 0 {
+		close(m.stopped)
+		m.stopped = make(chan struct{})
+	}
+}
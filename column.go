@@ -1,40 +1,64 @@
 package doric
 
-// maxTile is the maximum tile value a column can contain
-const maxTile = 6
+// Number of different colors tiles can have
+const (
+	// DefaultNumColors is the number of colors used if none is set in the game configuration
+	DefaultNumColors = 6
+	// MaxNumColors is the maximum number of colors a game can use
+	MaxNumColors = 9
+)
+
+// Default values for the length of columns and the number of aligned tiles needed to remove them
+const (
+	DefaultColumnLength = 3
+	DefaultMinMatch     = 3
+)
+
+// MagicJewel is the tile value of the multicolor jewels composing a magic column.
+// When a magic column lands, it destroys all the tiles in the well with the same
+// color as the one right underneath it.
+const MagicJewel = 10
 
 // TilesetBuilder defines the signature of the method to build a column tileset.
-type TilesetBuilder func(int) [3]int
+// It receives the number of colors in use and the length of columns, and must return a new slice
+// of that length with tiles with values between 1 and the number of colors.
+type TilesetBuilder func(numColors, length int) []int
 
 // Column represents a column to fall in the well
 type Column struct {
-	// Tileset composing the column. Tile at index 0 corresponds to upper one,
-	// while tile at index 2 refers to the bottom one. Possible tile values go from
-	// 1 to maxTile.
-	Tileset [3]int
+	// Tileset composing the column. Tile at index 0 corresponds to the bottom one,
+	// while the last tile refers to the upper one. Possible tile values go from
+	// 1 to the number of colors set in the game configuration (6 by default, up to MaxNumColors),
+	// or MagicJewel for all tiles in magic columns.
+	Tileset []int `json:"tileset"`
 	// Position of the column in the well, using its bottom tile as reference.
-	X, Y int
+	X int `json:"x"`
+	Y int `json:"y"`
 }
 
 // left moves the column to the left in the well if that position is empty
-// and not out of bounds
-func (p *Column) left(well Well) {
-	if p.X > 0 && well[p.X-1][p.Y] == Empty {
+// and not out of bounds. If the column cannot be moved, returns false.
+func (p *Column) left(well Well) bool {
+	if well.inBounds(p.X-1, p.Y) && well[p.X-1][p.Y] == Empty {
 		p.X--
+		return true
 	}
+	return false
 }
 
 // right moves the column to the right in the well if that position is empty
-// and not out of bounds
-func (p *Column) right(well Well) {
-	if p.X < well.width()-1 && well[p.X+1][p.Y] == Empty {
+// and not out of bounds. If the column cannot be moved, returns false.
+func (p *Column) right(well Well) bool {
+	if well.inBounds(p.X+1, p.Y) && well[p.X+1][p.Y] == Empty {
 		p.X++
+		return true
 	}
+	return false
 }
 
 // down moves the current column down in the well. If the column cannot fall further, returns false.
 func (p *Column) down(well Well) bool {
-	if p.Y < well.height()-1 && well[p.X][p.Y+1] == Empty {
+	if well.inBounds(p.X, p.Y+1) && well[p.X][p.Y+1] == Empty {
 		p.Y++
 		return true
 	}
@@ -43,13 +67,47 @@ func (p *Column) down(well Well) bool {
 
 // rotate rotates column tiles down. Last tile is moved to the first one
 func (p *Column) rotate() {
-	p.Tileset[0], p.Tileset[2] = p.Tileset[2], p.Tileset[0]
-	p.Tileset[1], p.Tileset[2] = p.Tileset[2], p.Tileset[1]
+	if len(p.Tileset) == 0 {
+		return
+	}
+	last := len(p.Tileset) - 1
+	tile := p.Tileset[last]
+	copy(p.Tileset[1:], p.Tileset[:last])
+	p.Tileset[0] = tile
+}
+
+// rotateReverse rotates column tiles up. First tile is moved to the last one
+func (p *Column) rotateReverse() {
+	if len(p.Tileset) == 0 {
+		return
+	}
+	last := len(p.Tileset) - 1
+	tile := p.Tileset[0]
+	copy(p.Tileset[:last], p.Tileset[1:])
+	p.Tileset[last] = tile
+}
+
+// isMagic returns true if the column is composed of magic jewels
+func (p *Column) isMagic() bool {
+	return len(p.Tileset) > 0 && p.Tileset[0] == MagicJewel
 }
 
 // reset copies the passed tileset, and resets its position to the initial one
-func (p *Column) reset(next [3]int, col int) {
-	p.Tileset = next
+func (p *Column) reset(next []int, col int) {
+	p.Tileset = copyTileset(next)
 	p.X = col
 	p.Y = 0
 }
+
+// copy returns a copy of the column which does not share its tileset with the original one
+func (p *Column) copy() Column {
+	return Column{
+		Tileset: copyTileset(p.Tileset),
+		X:       p.X,
+		Y:       p.Y,
+	}
+}
+
+func copyTileset(tileset []int) []int {
+	return append([]int(nil), tileset...)
+}
@@ -0,0 +1,90 @@
+package doric
+
+import "fmt"
+
+// Possible commands coming from the player. New commands are added at the end,
+// so values sent by existing clients keep their meaning.
+const (
+	// Move the current column left
+	CommandLeft Command = iota
+	// Move the current column right
+	CommandRight
+	// Move the current column down
+	CommandDown
+	// Rotate tiles in current column
+	CommandRotate
+	// Pause / unpause game (for player use)
+	CommandPauseSwitch
+	// Pause / unpause game (intended for internal use, e. g. stop game logic while play animation).
+	// Config.ClearDelay and Config.SpawnDelay can be used instead to wait for animations between chain steps
+	CommandWaitSwitch
+	// Quit game
+	CommandQuit
+	// Drop the current column to its final resting place immediately
+	CommandDrop
+	// Rotate tiles in current column in the opposite direction
+	CommandRotateReverse
+	// Request a snapshot of the game state, which is sent in an EventSnapshot
+	CommandSnapshot
+	// Move the current column to the well column passed as payload, as far as possible
+	// if there are tiles in the way (intended for touch or mouse controls). Use MoveTo to build it.
+	CommandMoveTo
+	// Put the game back in the position it started from. Only available in practice mode (see Config.Practice)
+	CommandRestart
+)
+
+// Commands carrying a payload store it in the bits above payloadShift, keeping its sign,
+// so commands with a negative payload are negative
+const payloadShift = 8
+
+// Command is an order sent by the player to a game. Some commands carry a payload,
+// like the target column of CommandMoveTo.
+type Command int
+
+var commandNames = map[Command]string{
+	CommandLeft:          "Left",
+	CommandRight:         "Right",
+	CommandDown:          "Down",
+	CommandDrop:          "Drop",
+	CommandRotate:        "Rotate",
+	CommandRotateReverse: "RotateReverse",
+	CommandPauseSwitch:   "PauseSwitch",
+	CommandWaitSwitch:    "WaitSwitch",
+	CommandSnapshot:      "Snapshot",
+	CommandQuit:          "Quit",
+	CommandMoveTo:        "MoveTo",
+	CommandRestart:       "Restart",
+}
+
+// MoveTo returns a CommandMoveTo command to move the current column to the passed well column
+func MoveTo(x int) Command {
+	return CommandMoveTo | Command(x<<payloadShift)
+}
+
+// Type returns the command without its payload, so it can be compared with the command constants
+func (c Command) Type() Command {
+	return c & (1<<payloadShift - 1)
+}
+
+// Payload returns the payload carried by the command, if any
+func (c Command) Payload() int {
+	return int(c >> payloadShift)
+}
+
+// Valid returns true if the command is one of the known ones, and only carries a payload if its type supports it
+func (c Command) Valid() bool {
+	if _, ok := commandNames[c.Type()]; !ok {
+		return false
+	}
+	return c.Type() == CommandMoveTo || c.Payload() == 0
+}
+
+func (c Command) String() string {
+	if !c.Valid() {
+		return fmt.Sprintf("Command(%d)", int(c))
+	}
+	if c.Type() == CommandMoveTo {
+		return fmt.Sprintf("MoveTo(%d)", c.Payload())
+	}
+	return commandNames[c]
+}
@@ -0,0 +1,67 @@
+package doric_test
+
+import (
+	"testing"
+
+	"github.com/svera/doric"
+)
+
+func TestCommandValid(t *testing.T) {
+	tests := []struct {
+		command  doric.Command
+		expected bool
+	}{
+		{command: doric.CommandLeft, expected: true},
+		{command: doric.CommandQuit, expected: true},
+		{command: doric.MoveTo(3), expected: true},
+		{command: doric.MoveTo(-1), expected: true},
+		{command: doric.Command(-1), expected: false},
+		{command: doric.Command(200), expected: false},
+		{command: doric.Command(3 << 8), expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.command.String(), func(t *testing.T) {
+			if test.command.Valid() != test.expected {
+				t.Errorf("Expected validity of %s to be %t", test.command, test.expected)
+			}
+		})
+	}
+}
+
+func TestCommandString(t *testing.T) {
+	tests := []struct {
+		command  doric.Command
+		expected string
+	}{
+		{command: doric.CommandRotate, expected: "Rotate"},
+		{command: doric.MoveTo(4), expected: "MoveTo(4)"},
+		{command: doric.MoveTo(-1), expected: "MoveTo(-1)"},
+		{command: doric.CommandRestart, expected: "Restart"},
+		{command: doric.Command(200), expected: "Command(200)"},
+	}
+
+	for _, test := range tests {
+		if str := test.command.String(); str != test.expected {
+			t.Errorf("Expected %q but got %q", test.expected, str)
+		}
+	}
+}
+
+func TestCommandValues(t *testing.T) {
+	// Values of the original commands must never change, as clients may send or store them as raw integers
+	expected := map[doric.Command]int{
+		doric.CommandLeft:        0,
+		doric.CommandRight:       1,
+		doric.CommandDown:        2,
+		doric.CommandRotate:      3,
+		doric.CommandPauseSwitch: 4,
+		doric.CommandWaitSwitch:  5,
+		doric.CommandQuit:        6,
+	}
+	for command, value := range expected {
+		if int(command) != value {
+			t.Errorf("Expected %s to be %d but got %d", command, value, int(command))
+		}
+	}
+}
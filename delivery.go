@@ -0,0 +1,90 @@
+package doric
+
+// Policies to follow when events are produced faster than they are read
+const (
+	// The game waits until there is room in the buffer, so no event is lost
+	PolicyBlock DropPolicy = iota
+	// The oldest EventUpdated waiting to be read is discarded when the buffer is full
+	PolicyDropOldestUpdate
+	// An EventUpdated waiting to be read is replaced by any newer one, as only the last
+	// position of the column matters
+	PolicyCoalesceUpdates
+)
+
+// DropPolicy tells what to do with events which cannot be delivered because the buffer is full.
+// Only EventUpdated events are ever discarded, as they are superseded by any later update.
+// With PolicyDropOldestUpdate and PolicyCoalesceUpdates, the buffer grows without limit if events
+// other than EventUpdated keep being produced (e. g. as columns land) while the reader is stalled.
+type DropPolicy int
+
+// eventQueue holds the events waiting to be read, applying the drop policy when it gets full.
+// Its first event is the one being offered to the reader, which does not count towards the buffer size.
+type eventQueue struct {
+	events  []Event
+	size    int
+	policy  DropPolicy
+	dropped uint64
+}
+
+func newEventQueue(size int, policy DropPolicy) *eventQueue {
+	return &eventQueue{
+		size:   size,
+		policy: policy,
+	}
+}
+
+// push adds the passed event to the queue, discarding updates as the drop policy says
+func (q *eventQueue) push(ev Event) {
+	_, isUpdate := ev.(EventUpdated)
+	if isUpdate && q.policy == PolicyCoalesceUpdates {
+		q.removeUpdates(len(q.events))
+	}
+	q.events = append(q.events, ev)
+	if len(q.events) > q.size+1 && q.policy == PolicyDropOldestUpdate {
+		q.removeUpdates(1)
+	}
+	if over, ok := ev.(EventGameOver); ok {
+		over.Dropped = q.dropped
+		q.events[len(q.events)-1] = over
+	}
+}
+
+// discard removes all events waiting to be read, counting them as dropped
+func (q *eventQueue) discard() {
+	q.dropped += uint64(len(q.events))
+	q.events = nil
+}
+
+// removeUpdates removes up to n EventUpdated events from the queue, starting from the oldest one
+func (q *eventQueue) removeUpdates(n int) {
+	kept := q.events[:0]
+	for _, ev := range q.events {
+		if _, ok := ev.(EventUpdated); ok && n > 0 {
+			n--
+			q.dropped++
+			continue
+		}
+		kept = append(kept, ev)
+	}
+	q.events = kept
+}
+
+// full returns true if the game must wait for events to be read before going on
+func (q *eventQueue) full() bool {
+	return q.policy == PolicyBlock && len(q.events) > q.size
+}
+
+// empty returns true if there are no events waiting to be read
+func (q *eventQueue) empty() bool {
+	return len(q.events) == 0
+}
+
+// first returns the oldest event in the queue
+func (q *eventQueue) first() Event {
+	return q.events[0]
+}
+
+// pop removes the oldest event from the queue
+func (q *eventQueue) pop() {
+	q.events = q.events[1:]
+}
@@ -0,0 +1,216 @@
+package doric
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// EncodingVersion is the version of the JSON and binary formats written by this package.
+// In JSON, it is written in every well, column, game state and event envelope, even when nested in another value.
+// In binary, it is written once at the beginning of every marshaled well, column, game state or event.
+const EncodingVersion = 1
+
+// Possible errors returned when decoding
+const (
+	errorUnsupportedEncodingVersion = "Unsupported encoding version %d"
+	errorUnknownEventKind           = "Unknown event kind %q"
+	errorMissingEventKind           = "Event kind is missing"
+)
+
+// eventTypes holds the concrete type of the events of every kind
+var eventTypes = map[EventKind]reflect.Type{
+	KindUpdated:      reflect.TypeOf(EventUpdated{}),
+	KindScored:       reflect.TypeOf(EventScored{}),
+	KindRenewed:      reflect.TypeOf(EventRenewed{}),
+	KindMagicJewel:   reflect.TypeOf(EventMagicJewel{}),
+	KindGameOver:     reflect.TypeOf(EventGameOver{}),
+	KindSnapshot:     reflect.TypeOf(EventSnapshot{}),
+	KindRejected:     reflect.TypeOf(EventRejected{}),
+	KindTouchedDown:  reflect.TypeOf(EventTouchedDown{}),
+	KindClearStarted: reflect.TypeOf(EventClearStarted{}),
+	KindSettled:      reflect.TypeOf(EventSettled{}),
+}
+
+// wellJSON is the JSON representation of Well, which includes the encoding version
+type wellJSON struct {
+	Version int      `json:"version"`
+	Rows    []string `json:"rows"`
+}
+
+// MarshalJSON implements json.Marshaler, writing the encoding version and the well rows from top to bottom
+// as text, e. g. {"version":1,"rows":["......","..1...",".12*34"]}
+func (p Well) MarshalJSON() ([]byte, error) {
+	text, err := p.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	rows := []string{}
+	if len(text) > 0 {
+		rows = strings.Split(string(text), "\n")
+	}
+	return json.Marshal(wellJSON{Version: EncodingVersion, Rows: rows})
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (p *Well) UnmarshalJSON(data []byte) error {
+	var w wellJSON
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+	if w.Version != EncodingVersion {
+		return fmt.Errorf(errorUnsupportedEncodingVersion, w.Version)
+	}
+	if len(w.Rows) == 0 {
+		*p = Well{}
+		return nil
+	}
+	return p.UnmarshalText([]byte(strings.Join(w.Rows, "\n")))
+}
+
+// column has the same fields as Column, but none of its methods
+type column Column
+
+// columnJSON is the JSON representation of Column, which includes the encoding version
+type columnJSON struct {
+	Version int `json:"version"`
+	column
+}
+
+// MarshalJSON implements json.Marshaler, adding the encoding version to the column fields
+func (c Column) MarshalJSON() ([]byte, error) {
+	return json.Marshal(columnJSON{Version: EncodingVersion, column: column(c)})
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (c *Column) UnmarshalJSON(data []byte) error {
+	var j columnJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	if j.Version != EncodingVersion {
+		return fmt.Errorf(errorUnsupportedEncodingVersion, j.Version)
+	}
+	*c = Column(j.column)
+	return nil
+}
+
+// gameState has the same fields as GameState, but none of its methods
+type gameState GameState
+
+// gameStateJSON is the JSON representation of GameState, which includes the encoding version
+type gameStateJSON struct {
+	Version int `json:"version"`
+	gameState
+}
+
+// MarshalJSON implements json.Marshaler, adding the encoding version to the state fields
+func (s GameState) MarshalJSON() ([]byte, error) {
+	return json.Marshal(gameStateJSON{Version: EncodingVersion, gameState: gameState(s)})
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (s *GameState) UnmarshalJSON(data []byte) error {
+	var g gameStateJSON
+	if err := json.Unmarshal(data, &g); err != nil {
+		return err
+	}
+	if g.Version != EncodingVersion {
+		return fmt.Errorf(errorUnsupportedEncodingVersion, g.Version)
+	}
+	*s = GameState(g.gameState)
+	return nil
+}
+
+// gameOverJSON is the JSON representation of EventGameOver, which holds the error message
+// instead of the error itself
+type gameOverJSON struct {
+	gameOver
+	Err string `json:"err,omitempty"`
+}
+
+// gameOver has the same fields as EventGameOver, but none of its methods
+type gameOver EventGameOver
+
+// MarshalJSON implements json.Marshaler. Only the message of Err is written.
+func (e EventGameOver) MarshalJSON() ([]byte, error) {
+	g := gameOverJSON{gameOver: gameOver(e)}
+	if e.Err != nil {
+		g.Err = e.Err.Error()
+	}
+	return json.Marshal(g)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. If an error message was written, Err holds a new
+// error with that message, so it cannot be compared with the original one.
+func (e *EventGameOver) UnmarshalJSON(data []byte) error {
+	var g gameOverJSON
+	if err := json.Unmarshal(data, &g); err != nil {
+		return err
+	}
+	*e = EventGameOver(g.gameOver)
+	if g.Err != "" {
+		e.Err = errors.New(g.Err)
+	}
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler, writing the kind as String does
+func (k EventKind) MarshalText() ([]byte, error) {
+	if _, ok := eventTypes[k]; !ok {
+		return nil, fmt.Errorf(errorUnknownEventKind, k.String())
+	}
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (k *EventKind) UnmarshalText(text []byte) error {
+	for kind := range eventTypes {
+		if kind.String() == string(text) {
+			*k = kind
+			return nil
+		}
+	}
+	return fmt.Errorf(errorUnknownEventKind, text)
+}
+
+// eventJSON is the JSON envelope of an event, which tells its kind so it can be decoded.
+// Kind is a pointer so a missing kind is not taken as KindUpdated.
+type eventJSON struct {
+	Version int             `json:"version"`
+	Kind    *EventKind      `json:"kind"`
+	Event   json.RawMessage `json:"event"`
+}
+
+// MarshalEventJSON returns the passed event in JSON format, wrapped in an envelope
+// with the encoding version and the event kind, e. g. {"version":1,"kind":"scored","event":{...}}
+func MarshalEventJSON(ev Event) ([]byte, error) {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return nil, err
+	}
+	kind := ev.Kind()
+	return json.Marshal(eventJSON{Version: EncodingVersion, Kind: &kind, Event: data})
+}
+
+// UnmarshalEventJSON returns the event written in the passed data by MarshalEventJSON.
+// The returned event has the same concrete type as the original one, e. g. EventScored.
+func UnmarshalEventJSON(data []byte) (Event, error) {
+	var env eventJSON
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, err
+	}
+	if env.Version != EncodingVersion {
+		return nil, fmt.Errorf(errorUnsupportedEncodingVersion, env.Version)
+	}
+	if env.Kind == nil {
+		return nil, fmt.Errorf(errorMissingEventKind)
+	}
+	ev := reflect.New(eventTypes[*env.Kind])
+	if err := json.Unmarshal(env.Event, ev.Interface()); err != nil {
+		return nil, err
+	}
+	return ev.Elem().Interface().(Event), nil
+}
@@ -0,0 +1,196 @@
+package doric_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/svera/doric"
+)
+
+// sampleEvents returns an event of every kind
+func sampleEvents(t *testing.T) []doric.Event {
+	well := parseWell(t, `
+		......
+		...x..
+		.12*x3
+	`)
+	column := doric.Column{Tileset: []int{1, 2, 3}, X: 3, Y: 1}
+	header := doric.EventHeader{Seq: 7, Tick: 42}
+	return []doric.Event{
+		doric.EventUpdated{EventHeader: header, Column: column, LandingY: 2},
+		doric.EventScored{EventHeader: header, Well: well, Combo: 2, Removed: 3, Level: 1, Points: 180, Score: 272,
+			Matches: []doric.Match{
+				{Direction: doric.DirectionDiagonalUp, Length: 3, Color: 1, Cells: []doric.Position{{X: 1, Y: 2}, {X: 2, Y: 1}, {X: 3, Y: 0}}},
+			},
+		},
+		doric.EventRenewed{EventHeader: header, Well: well, Column: column, NextTileset: []int{4, 5, 6}, LandingY: 2, Score: 10},
+		doric.EventMagicJewel{EventHeader: header, Well: well, Color: 2, Removed: 4, Level: 3, Points: 360, Score: 400},
+		doric.EventGameOver{EventHeader: header, Reason: doric.ReasonCancelled, Well: well, Level: 2, Score: 500,
+			TotalRemoved: 15, MaxCombo: 3, Elapsed: 90 * time.Second, Err: errors.New("context canceled"), Dropped: 5},
+		doric.EventSnapshot{EventHeader: header, State: doric.GameState{Well: well, Column: column, NextTileset: []int{4, 5, 6},
+			Level: 2, Speed: 1.5, TotalRemoved: 12, MaxCombo: 2, Score: 300, TouchedDown: true, LockTicks: 1, Drawn: 9, StartLevel: 2}},
+		doric.EventRejected{EventHeader: header, Command: doric.MoveTo(4), Reason: doric.RejectOutOfBounds},
+		doric.EventTouchedDown{EventHeader: header, Column: column, LockDelay: 3},
+		doric.EventClearStarted{EventHeader: header, Combo: 1, Delay: 5},
+		doric.EventSettled{EventHeader: header, Well: well,
+			Moves: []doric.Move{{From: doric.Position{X: 3, Y: 0}, To: doric.Position{X: 3, Y: 2}, Tile: 3}},
+		},
+	}
+}
+
+func TestEventRoundTrip(t *testing.T) {
+	codecs := []struct {
+		name      string
+		marshal   func(doric.Event) ([]byte, error)
+		unmarshal func([]byte) (doric.Event, error)
+	}{
+		{name: "JSON", marshal: doric.MarshalEventJSON, unmarshal: doric.UnmarshalEventJSON},
+		{name: "binary", marshal: doric.MarshalEventBinary, unmarshal: doric.UnmarshalEventBinary},
+	}
+
+	for _, codec := range codecs {
+		for _, ev := range sampleEvents(t) {
+			t.Run(codec.name+" "+ev.Kind().String(), func(t *testing.T) {
+				data, err := codec.marshal(ev)
+				if err != nil {
+					t.Fatalf("Expected no error but got %v", err)
+				}
+				decoded, err := codec.unmarshal(data)
+				if err != nil {
+					t.Fatalf("Expected no error but got %v", err)
+				}
+				if !reflect.DeepEqual(ev, decoded) {
+					t.Errorf("Expected event %#v but got %#v", ev, decoded)
+				}
+			})
+		}
+	}
+}
+
+func TestEventJSONEnvelope(t *testing.T) {
+	data, err := doric.MarshalEventJSON(doric.EventClearStarted{EventHeader: doric.EventHeader{Seq: 1, Tick: 2}, Combo: 1, Delay: 5})
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	expected := `{"version":1,"kind":"clear started","event":{"seq":1,"tick":2,"combo":1,"delay":5}}`
+	if string(data) != expected {
+		t.Errorf("Expected %s but got %s", expected, data)
+	}
+
+	invalid := []string{
+		`{"version":2,"kind":"clear started","event":{}}`,
+		`{"version":1,"kind":"exploded","event":{}}`,
+		`{"version":1,"event":{"column":{"tileset":[1,2,3],"x":0,"y":0}}}`,
+	}
+	for _, data := range invalid {
+		if _, err := doric.UnmarshalEventJSON([]byte(data)); err == nil {
+			t.Errorf("Expected error decoding %s", data)
+		}
+	}
+}
+
+func TestGameStateJSONVersion(t *testing.T) {
+	state := doric.GameState{Well: doric.NewWell(3, 2), Column: doric.Column{Tileset: []int{1, 2, 3}}, Level: 1, Speed: 1}
+	data, err := json.Marshal(state)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	expected := `{"version":1,"well":{"version":1,"rows":["...","..."]},"column":{"version":1,"tileset":[1,2,3],"x":0,"y":0},` +
+		`"nextTileset":null,` +
+		`"level":1,"speed":1,"totalRemoved":0,"maxCombo":0,"score":0,"dropped":0,"touchedDown":false,"lockTicks":0,` +
+		`"lockResets":0,"clearing":false,"spawning":false,"delay":0,"combo":0,"drawn":0,"startLevel":0}`
+	if string(data) != expected {
+		t.Errorf("Expected %s but got %s", expected, data)
+	}
+
+	var decoded doric.GameState
+	if err := json.Unmarshal([]byte(`{"version":2,"level":1}`), &decoded); err == nil {
+		t.Errorf("Expected error decoding a state with an unsupported version")
+	}
+}
+
+func TestWellAndColumnRoundTrip(t *testing.T) {
+	well := parseWell(t, `
+		.....
+		..x..
+		1*9.2
+	`)
+	column := doric.Column{Tileset: []int{doric.MagicJewel, 2, 3}, X: 4, Y: -1}
+
+	data, err := json.Marshal(well)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	if expected := `{"version":1,"rows":[".....","..x..","1*9.2"]}`; string(data) != expected {
+		t.Errorf("Expected %s but got %s", expected, data)
+	}
+	if err := json.Unmarshal([]byte(`{"version":2,"rows":["..."]}`), &doric.Well{}); err == nil {
+		t.Errorf("Expected error decoding a well with an unsupported version")
+	}
+	if err := json.Unmarshal([]byte(`{"tileset":[1,2,3],"x":0,"y":0}`), &doric.Column{}); err == nil {
+		t.Errorf("Expected error decoding a column without version")
+	}
+
+	tests := []struct {
+		name      string
+		value     interface{}
+		marshal   func(interface{}) ([]byte, error)
+		unmarshal func([]byte, interface{}) error
+		decoded   interface{}
+	}{
+		{name: "Well JSON", value: well, marshal: json.Marshal, unmarshal: json.Unmarshal, decoded: &doric.Well{}},
+		{name: "Column JSON", value: column, marshal: json.Marshal, unmarshal: json.Unmarshal, decoded: &doric.Column{}},
+		{name: "Well binary", value: well, marshal: marshalBinary, unmarshal: unmarshalBinary, decoded: &doric.Well{}},
+		{name: "Column binary", value: column, marshal: marshalBinary, unmarshal: unmarshalBinary, decoded: &doric.Column{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := test.marshal(test.value)
+			if err != nil {
+				t.Fatalf("Expected no error but got %v", err)
+			}
+			if err := test.unmarshal(data, test.decoded); err != nil {
+				t.Fatalf("Expected no error but got %v", err)
+			}
+			if decoded := reflect.ValueOf(test.decoded).Elem().Interface(); !reflect.DeepEqual(test.value, decoded) {
+				t.Errorf("Expected %v but got %v", test.value, decoded)
+			}
+		})
+	}
+}
+
+func TestUnmarshalBinaryErrors(t *testing.T) {
+	data, err := doric.NewWell(6, 13).MarshalBinary()
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	invalid := map[string][]byte{
+		"Must return error if data is truncated":         data[:len(data)-1],
+		"Must return error if there is data left":        append(data, 0),
+		"Must return error if the version is not known":  append([]byte{2}, data[1:]...),
+		"Must return error if the dimensions are absurd": {1, 0xff, 0xff, 0xff, 0xff, 0x0f, 2},
+	}
+	if _, err := (doric.Well{{0, 0}, {0}, {0, 0}}).MarshalBinary(); err == nil {
+		t.Errorf("Expected error encoding a well whose columns have different heights")
+	}
+	for name, data := range invalid {
+		t.Run(name, func(t *testing.T) {
+			var well doric.Well
+			if err := well.UnmarshalBinary(data); err == nil {
+				t.Errorf("Expected error decoding %v", data)
+			}
+		})
+	}
+}
+
+func marshalBinary(v interface{}) ([]byte, error) {
+	return v.(interface{ MarshalBinary() ([]byte, error) }).MarshalBinary()
+}
+
+func unmarshalBinary(data []byte, v interface{}) error {
+	return v.(interface{ UnmarshalBinary([]byte) error }).UnmarshalBinary(data)
+}
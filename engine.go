@@ -0,0 +1,558 @@
+package doric
+
+import (
+	"fmt"
+	"time"
+)
+
+// Phases a game goes through every time a column lands
+const (
+	// The current column is falling
+	phaseFalling phase = iota
+	// Marked tiles are about to be removed
+	phaseClearing
+	// A new column is about to enter the well
+	phaseSpawning
+)
+
+type phase int
+
+// Engine holds the state and the logic of a game, but unlike Play, it does not run
+// on its own. Time only advances when Tick is called, and commands are processed
+// synchronously with Step, which makes it suitable for tests, replays or any
+// other tool which needs to run games in a deterministic way.
+// An Engine is not safe for concurrent use.
+type Engine struct {
+	well         Well
+	column       *Column
+	nextTileset  []int
+	level        int
+	startLevel   int
+	paused       bool
+	wait         bool
+	over         bool
+	totalRemoved int
+	maxCombo     int
+	score        int
+	dropped      int
+	phase        phase
+	delay        int
+	combo        int
+	touchedDown  bool
+	lockTicks    int
+	lockResets   int
+	drawn        int
+	resumed      bool
+	initial      GameState
+	history      [][]int
+	replayed     int
+	ticks        int
+	seq          uint64
+	recording    *Recording
+	scorer       Scorer
+	cfg          Config
+	clock        Clock
+	started      time.Time
+	pausedAt     time.Time
+	pausedTime   time.Duration
+	speed        float64
+	build        TilesetBuilder
+	events       []Event
+}
+
+// NewEngine returns a new Engine instance which will play on a copy of the passed well,
+// or an error if the passed configuration or well, or the first tileset returned by the builder, are not valid.
+// If the builder returns an invalid tileset later, the game ends with ReasonInvalidTileset.
+func NewEngine(p Well, build TilesetBuilder, cfg Config) (*Engine, error) {
+	if err := validateConfig(cfg); err != nil {
+		return nil, err
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	next := build(cfg.numColors(), cfg.columnLength())
+	if err := validateTileset(next, cfg); err != nil {
+		return nil, fmt.Errorf(errorInvalidBuiltTileset, next, err)
+	}
+
+	if cfg.Recording != nil {
+		cfg.Recording.Well = p.Clone()
+		cfg.Recording.Commands = nil
+		cfg.Recording.Ticks = 0
+	}
+
+	e := &Engine{
+		well:        p.Clone(),
+		column:      &Column{},
+		nextTileset: next,
+		drawn:       1,
+		level:       cfg.startLevel(),
+		startLevel:  cfg.startLevel(),
+		cfg:         cfg,
+		scorer:      cfg.scorer(),
+		clock:       cfg.clock(),
+		speed:       cfg.InitialSpeed,
+		build:       build,
+		recording:   cfg.Recording,
+	}
+	for level := 1; level < e.level; level++ {
+		e.speedUp()
+	}
+	return e, nil
+}
+
+// Start puts the first column in the well, and returns the events produced.
+// If the engine was created from a game state, the column in that state is used instead. If that state
+// was taken while tiles were being cleared, an EventClearStarted with the ticks left is sent instead, and if
+// it was taken while waiting for a new column, no event is sent until the column enters the well.
+// It must be called once, before any call to Step or Tick.
+func (e *Engine) Start() []Event {
+	e.started = e.clock.Now()
+	if e.resumed {
+		e.emitResumed()
+	} else {
+		e.renewColumn()
+	}
+	if e.cfg.Practice {
+		// Tilesets drawn so far are part of the initial state, only the ones after it need to be kept
+		e.initial = e.State()
+		e.history = nil
+		e.replayed = 0
+	}
+	return e.flush()
+}
+
+// Step executes the passed command, and returns the events produced.
+func (e *Engine) Step(comm Command) []Event {
+	if e.over {
+		return nil
+	}
+	if e.recording != nil {
+		e.recording.Commands = append(e.recording.Commands, RecordedCommand{Tick: e.ticks, Command: comm})
+	}
+	e.execute(comm)
+	return e.flush()
+}
+
+// Tick advances game time one step, making the current column fall one cell.
+// If the column cannot fall further, it is locked in the well (after the lock delay set in the configuration,
+// if any), tiles are removed if possible and a new column enters the well. If clear or spawn delays are set
+// in the configuration, ticks are spent waiting for them instead. Returns the events produced.
+func (e *Engine) Tick() []Event {
+	if e.over {
+		return nil
+	}
+	e.ticks++
+	if e.recording != nil {
+		e.recording.Ticks = e.ticks
+	}
+	if e.paused || e.wait {
+		return nil
+	}
+	if e.phase != phaseFalling {
+		e.delay--
+		e.resolve()
+		return e.flush()
+	}
+	if e.column.down(e.well) {
+		e.emit(EventUpdated{
+			EventHeader: e.header(),
+			Column:      e.column.copy(),
+			LandingY:    e.well.LandingY(*e.column),
+		})
+		return e.flush()
+	}
+	if e.cfg.LockDelay > 0 {
+		if !e.touchedDown {
+			e.touchDown()
+			return e.flush()
+		}
+		e.lockTicks--
+		if e.lockTicks > 0 {
+			return e.flush()
+		}
+	}
+	e.land()
+	return e.flush()
+}
+
+// Cancel ends the game from outside, with the passed error as cause, and returns the events produced.
+func (e *Engine) Cancel(err error) []Event {
+	if e.over {
+		return nil
+	}
+	e.end(ReasonCancelled, err)
+	return e.flush()
+}
+
+// Ticks returns the number of times Tick was called since the game started
+func (e *Engine) Ticks() int {
+	return e.ticks
+}
+
+// Speed returns the speed at which columns must fall at the current level, in cells/second.
+func (e *Engine) Speed() float64 {
+	return e.speed
+}
+
+// IsOver returns true if the game has finished, either because the player quitted
+// or because no more columns can enter the well.
+func (e *Engine) IsOver() bool {
+	return e.over
+}
+
+func (e *Engine) execute(comm Command) {
+	if !comm.Valid() {
+		e.reject(comm, RejectInvalid)
+		return
+	}
+	switch comm {
+	case CommandQuit:
+		e.end(ReasonQuit, nil)
+		return
+	case CommandWaitSwitch:
+		e.wait = !e.wait
+		return
+	case CommandSnapshot:
+		e.emit(EventSnapshot{
+			EventHeader: e.header(),
+			State:       e.State(),
+		})
+		return
+	case CommandPauseSwitch:
+		e.paused = !e.paused
+		if e.paused {
+			e.pausedAt = e.clock.Now()
+		} else {
+			e.pausedTime += e.clock.Now().Sub(e.pausedAt)
+		}
+		return
+	}
+	if e.paused || e.wait {
+		e.reject(comm, RejectPaused)
+		return
+	}
+	if comm == CommandRestart {
+		if !e.cfg.Practice {
+			e.reject(comm, RejectNotPractice)
+			return
+		}
+		e.restart()
+		return
+	}
+	if e.phase != phaseFalling {
+		e.reject(comm, RejectNotFalling)
+		return
+	}
+	moved := false
+	switch comm.Type() {
+	case CommandDrop:
+		for e.column.down(e.well) {
+			e.dropped++
+		}
+		e.land()
+		return
+	case CommandMoveTo:
+		x := e.column.X
+		if !e.moveTo(comm.Payload()) {
+			e.reject(comm, RejectOutOfBounds)
+			return
+		}
+		moved = e.column.X != x
+	case CommandLeft:
+		moved = e.column.left(e.well)
+	case CommandRight:
+		moved = e.column.right(e.well)
+	case CommandDown:
+		if e.column.down(e.well) {
+			e.dropped++
+		}
+	case CommandRotate:
+		e.column.rotate()
+		moved = true
+	case CommandRotateReverse:
+		e.column.rotateReverse()
+		moved = true
+	}
+	if moved {
+		e.resetLockDelay()
+	}
+	e.emit(EventUpdated{
+		EventHeader: e.header(),
+		Column:      e.column.copy(),
+		LandingY:    e.well.LandingY(*e.column),
+	})
+}
+
+// touchDown starts the lock delay of the current column, which cannot fall further
+func (e *Engine) touchDown() {
+	e.touchedDown = true
+	e.lockTicks = e.cfg.LockDelay
+	e.emit(EventTouchedDown{
+		EventHeader: e.header(),
+		Column:      e.column.copy(),
+		LockDelay:   e.lockTicks,
+	})
+}
+
+// resetLockDelay restarts the lock delay of a column which touched down and was moved or rotated,
+// as long as the resets limit is not reached. If the column can fall again, it is not touching down anymore.
+func (e *Engine) resetLockDelay() {
+	if !e.touchedDown {
+		return
+	}
+	if e.well.LandingY(*e.column) > e.column.Y {
+		e.touchedDown = false
+		return
+	}
+	if e.lockResets < e.cfg.LockResets {
+		e.lockResets++
+		e.lockTicks = e.cfg.LockDelay
+	}
+}
+
+// moveTo moves the current column towards the passed well column until it gets there or
+// there are tiles in the way. Returns false if the passed column is out of the well.
+func (e *Engine) moveTo(x int) bool {
+	if x < 0 || x >= e.well.Width() {
+		return false
+	}
+	for e.column.X > x && e.column.left(e.well) {
+	}
+	for e.column.X < x && e.column.right(e.well) {
+	}
+	return true
+}
+
+func (e *Engine) reject(comm Command, reason RejectionReason) {
+	e.emit(EventRejected{
+		EventHeader: e.header(),
+		Command:     comm,
+		Reason:      reason,
+	})
+}
+
+// land locks the current column in the well and starts removing tiles. If there are no delays
+// set in the configuration, the chain is fully resolved and a new column enters the well right away.
+func (e *Engine) land() {
+	e.well.lock(e.column)
+	e.score += e.scorer.Dropped(e.dropped, e.level)
+	e.combo = 0
+	if e.column.isMagic() {
+		color, removed := e.well.markColor(e.column)
+		e.addRemoved(removed)
+		points := e.scorer.Scored(removed, 1, e.level)
+		e.score += points
+		e.emit(EventMagicJewel{
+			EventHeader: e.header(),
+			Well:        e.well.Clone(),
+			Color:       color,
+			Removed:     removed,
+			Level:       e.level,
+			Points:      points,
+			Score:       e.score,
+		})
+		e.startClear()
+	} else {
+		e.removeLines()
+	}
+	e.resolve()
+}
+
+// removeLines marks aligned tiles to be removed, starting a new step of the chain.
+// If there are no tiles to remove, the chain is over and a new column is about to enter the well.
+func (e *Engine) removeLines() {
+	removed, matches := e.well.markTilesToRemove(e.cfg.minMatch())
+	if removed == 0 {
+		e.phase = phaseSpawning
+		e.delay = e.cfg.SpawnDelay
+		return
+	}
+	e.combo++
+	e.addRemoved(removed)
+	points := e.scorer.Scored(removed, e.combo, e.level)
+	e.score += points
+	e.emit(EventScored{
+		EventHeader: e.header(),
+		Well:        e.well.Clone(),
+		Combo:       e.combo,
+		Level:       e.level,
+		Removed:     removed,
+		Matches:     matches,
+		Points:      points,
+		Score:       e.score,
+	})
+	if e.combo > e.maxCombo {
+		e.maxCombo = e.combo
+	}
+	e.startClear()
+}
+
+// startClear waits for the clear delay before removing the marked tiles from the well
+func (e *Engine) startClear() {
+	e.phase = phaseClearing
+	e.delay = e.cfg.ClearDelay
+	e.emit(EventClearStarted{
+		EventHeader: e.header(),
+		Combo:       e.combo,
+		Delay:       e.delay,
+	})
+}
+
+// resolve removes marked tiles and puts a new column in the well, as far as delays allow
+func (e *Engine) resolve() {
+	for e.delay == 0 {
+		switch e.phase {
+		case phaseClearing:
+			moves := e.well.settle()
+			e.emit(EventSettled{
+				EventHeader: e.header(),
+				Well:        e.well.Clone(),
+				Moves:       moves,
+			})
+			e.removeLines()
+		case phaseSpawning:
+			e.phase = phaseFalling
+			e.renewColumn()
+			if !e.over && e.isOver() {
+				e.end(ReasonToppedOut, nil)
+			}
+			return
+		default:
+			return
+		}
+	}
+}
+
+// addRemoved adds the passed number of removed tiles to the total, increasing level if needed.
+// Tiles needed to reach the start level count as already removed.
+func (e *Engine) addRemoved(removed int) {
+	e.totalRemoved += removed
+	if e.cfg.NumberTilesForNextLevel == 0 {
+		return
+	}
+	baseline := (e.startLevel - 1) * e.cfg.NumberTilesForNextLevel
+	if (baseline+e.totalRemoved)/e.cfg.NumberTilesForNextLevel > e.level-1 {
+		e.level++
+		e.speedUp()
+	}
+}
+
+func (e *Engine) speedUp() {
+	speed := e.speed + e.cfg.SpeedIncrement
+	if speed < e.cfg.MaxSpeed {
+		e.speed = speed
+	}
+}
+
+func (e *Engine) isOver() bool {
+	return e.well[e.well.Width()/2][0] != Empty
+}
+
+// renewColumn puts the next column in the well and draws a new one, ending the game
+// if the builder returns an invalid tileset
+func (e *Engine) renewColumn() {
+	e.column.reset(e.nextTileset, e.well.Width()/2)
+	next := e.draw()
+	if err := validateTileset(next, e.cfg); err != nil {
+		e.end(ReasonInvalidTileset, fmt.Errorf(errorInvalidBuiltTileset, next, err))
+		return
+	}
+	e.nextTileset = next
+	e.dropped = 0
+	e.touchedDown = false
+	e.lockResets = 0
+	e.emitRenewed()
+}
+
+// draw returns a new tileset from the builder. In practice mode, tilesets are kept so the same
+// sequence is returned again after restarting, without calling the builder.
+func (e *Engine) draw() []int {
+	if !e.cfg.Practice {
+		e.drawn++
+		return e.build(e.cfg.numColors(), e.cfg.columnLength())
+	}
+	if e.replayed == len(e.history) {
+		e.drawn++
+		e.history = append(e.history, e.build(e.cfg.numColors(), e.cfg.columnLength()))
+	}
+	e.replayed++
+	return copyTileset(e.history[e.replayed-1])
+}
+
+// restart puts the game back in the position it started from. The number of tilesets built
+// is kept, as the builder is not rewound.
+func (e *Engine) restart() {
+	drawn := e.drawn
+	e.restore(e.initial)
+	e.drawn = drawn
+	e.replayed = 0
+	e.emitResumed()
+}
+
+// emitResumed tells the current position of a game restored from a state, without presenting a column
+// which already landed as the falling one
+func (e *Engine) emitResumed() {
+	switch e.phase {
+	case phaseFalling:
+		e.emitRenewed()
+	case phaseClearing:
+		e.emit(EventClearStarted{
+			EventHeader: e.header(),
+			Combo:       e.combo,
+			Delay:       e.delay,
+		})
+	}
+}
+
+func (e *Engine) emitRenewed() {
+	e.emit(EventRenewed{
+		EventHeader: e.header(),
+		Well:        e.well.Clone(),
+		Column:      e.column.copy(),
+		NextTileset: copyTileset(e.nextTileset),
+		LandingY:    e.well.LandingY(*e.column),
+		Score:       e.score,
+	})
+}
+
+// end finishes the game, sending its final statistics
+func (e *Engine) end(reason GameOverReason, err error) {
+	e.over = true
+	elapsed := e.clock.Now().Sub(e.started) - e.pausedTime
+	if e.paused {
+		elapsed -= e.clock.Now().Sub(e.pausedAt)
+	}
+	e.emit(EventGameOver{
+		EventHeader:  e.header(),
+		Reason:       reason,
+		Well:         e.well.Clone(),
+		Level:        e.level,
+		Score:        e.score,
+		TotalRemoved: e.totalRemoved,
+		MaxCombo:     e.maxCombo,
+		Elapsed:      elapsed,
+		Err:          err,
+	})
+}
+
+// header returns the header for the next event
+func (e *Engine) header() EventHeader {
+	e.seq++
+	return EventHeader{
+		Seq:  e.seq,
+		Tick: e.ticks,
+	}
+}
+
+func (e *Engine) emit(ev Event) {
+	e.events = append(e.events, ev)
+}
+
+// flush returns the events produced since the last call and empties the queue
+func (e *Engine) flush() []Event {
+	events := e.events
+	e.events = nil
+	return events
+}
@@ -0,0 +1,765 @@
+package doric_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/svera/doric"
+)
+
+func newEngine(t *testing.T, cfg doric.Config, well doric.Well, ts [][]int) *doric.Engine {
+	factory := &mockTilesetBuilder{
+		Tilesets: ts,
+	}
+	engine, err := doric.NewEngine(well, factory.build, cfg)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	return engine
+}
+
+func TestEngineStart(t *testing.T) {
+	engine := newEngine(t, defaultConfig(), doric.NewWell(doric.StandardWidth, doric.StandardHeight), [][]int{{1, 2, 3}, {4, 5, 6}})
+
+	events := engine.Start()
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event but got %d", len(events))
+	}
+	renewed, ok := events[0].(doric.EventRenewed)
+	if !ok {
+		t.Fatalf("Expected an EventRenewed but got %T", events[0])
+	}
+	expected := doric.Column{Tileset: []int{1, 2, 3}, X: 3, Y: 0}
+	if !reflect.DeepEqual(renewed.Column, expected) {
+		t.Errorf("Expected column %v but got %v", expected, renewed.Column)
+	}
+	if !reflect.DeepEqual(renewed.NextTileset, []int{4, 5, 6}) {
+		t.Errorf("Expected next tileset %v but got %v", []int{4, 5, 6}, renewed.NextTileset)
+	}
+}
+
+func TestEngineTick(t *testing.T) {
+	engine := newEngine(t, defaultConfig(), doric.NewWell(doric.StandardWidth, 2), [][]int{{1, 2, 3}, {4, 5, 6}})
+	engine.Start()
+
+	events := engine.Tick()
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event but got %d", len(events))
+	}
+	if upd, ok := events[0].(doric.EventUpdated); !ok || upd.Column.Y != 1 {
+		t.Errorf("Expected column to fall one cell, got %v", events[0])
+	}
+
+	events = engine.Tick()
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events but got %d", len(events))
+	}
+	renewed, ok := events[0].(doric.EventRenewed)
+	if !ok {
+		t.Fatalf("Expected an EventRenewed but got %T", events[0])
+	}
+	if renewed.Well[3][1] != 1 || renewed.Well[3][0] != 2 {
+		t.Errorf("Expected column to be locked in the well, got %v", renewed.Well)
+	}
+	if over, ok := events[1].(doric.EventGameOver); !ok || over.Reason != doric.ReasonToppedOut {
+		t.Errorf("Expected game to be over because well is topped out, got %v", events[1])
+	}
+	if !engine.IsOver() {
+		t.Errorf("Expected game to be over")
+	}
+	if events := engine.Tick(); events != nil {
+		t.Errorf("Expected no events after game is over, got %v", events)
+	}
+}
+
+func TestEngineStep(t *testing.T) {
+	engine := newEngine(t, defaultConfig(), doric.NewWell(doric.StandardWidth, doric.StandardHeight), [][]int{{1, 2, 3}})
+	engine.Start()
+
+	events := engine.Step(doric.CommandLeft)
+	expected := doric.EventUpdated{
+		EventHeader: doric.EventHeader{Seq: 2, Tick: 0},
+		Column:      doric.Column{Tileset: []int{1, 2, 3}, X: 2, Y: 0},
+		LandingY:    12,
+	}
+	if len(events) != 1 || !reflect.DeepEqual(events[0], expected) {
+		t.Errorf("Expected %v but got %v", expected, events)
+	}
+
+	engine.Step(doric.CommandPauseSwitch)
+	if events := engine.Tick(); events != nil {
+		t.Errorf("Expected no events while paused, got %v", events)
+	}
+
+	engine.Step(doric.CommandQuit)
+	if !engine.IsOver() {
+		t.Errorf("Expected game to be over after quitting")
+	}
+}
+
+func TestEngineEventHeader(t *testing.T) {
+	engine := newEngine(t, defaultConfig(), doric.NewWell(doric.StandardWidth, 3), [][]int{{1, 2, 3}, {4, 5, 6}})
+	events := engine.Start()
+	events = append(events, engine.Tick()...)
+	events = append(events, engine.Step(doric.CommandLeft)...)
+	events = append(events, engine.Tick()...)
+	events = append(events, engine.Tick()...)
+
+	expected := []struct {
+		kind doric.EventKind
+		tick int
+	}{
+		{kind: doric.KindRenewed, tick: 0},
+		{kind: doric.KindUpdated, tick: 1},
+		{kind: doric.KindUpdated, tick: 1},
+		{kind: doric.KindUpdated, tick: 2},
+		{kind: doric.KindRenewed, tick: 3},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events but got %d", len(expected), len(events))
+	}
+	for i, exp := range expected {
+		if events[i].Kind() != exp.kind {
+			t.Errorf("Expected event %d to be of kind %s but got %s", i, exp.kind, events[i].Kind())
+		}
+		if events[i].Sequence() != uint64(i+1) {
+			t.Errorf("Expected event %d to have sequence number %d but got %d", i, i+1, events[i].Sequence())
+		}
+		if events[i].GameTick() != exp.tick {
+			t.Errorf("Expected event %d to be produced at tick %d but got %d", i, exp.tick, events[i].GameTick())
+		}
+	}
+}
+
+func TestEngineGameOver(t *testing.T) {
+	clock := doric.NewManualClock(time.Now())
+	cfg := defaultConfig()
+	cfg.Clock = clock
+	engine := newEngine(t, cfg, doric.NewWell(doric.StandardWidth, doric.StandardHeight), [][]int{{1, 2, 3}})
+	engine.Start()
+	clock.Advance(10 * time.Second)
+	engine.Step(doric.CommandPauseSwitch)
+	clock.Advance(5 * time.Second)
+	engine.Step(doric.CommandPauseSwitch)
+	clock.Advance(10 * time.Second)
+
+	events := engine.Step(doric.CommandQuit)
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event but got %d", len(events))
+	}
+	over, ok := events[0].(doric.EventGameOver)
+	if !ok {
+		t.Fatalf("Expected an EventGameOver but got %T", events[0])
+	}
+	if over.Reason != doric.ReasonQuit {
+		t.Errorf("Expected reason %s but got %s", doric.ReasonQuit, over.Reason)
+	}
+	if over.Elapsed != 20*time.Second {
+		t.Errorf("Expected elapsed time %s but got %s", 20*time.Second, over.Elapsed)
+	}
+	if over.Level != 1 {
+		t.Errorf("Expected level %d but got %d", 1, over.Level)
+	}
+}
+
+func TestEngineSpeedUp(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.NumberTilesForNextLevel = 1
+	engine := newEngine(t, cfg, doric.NewWell(doric.StandardWidth, 3), [][]int{{1, 1, 1}, {2, 3, 4}})
+	engine.Start()
+	engine.Tick()
+	engine.Tick()
+	engine.Tick()
+
+	if engine.Speed() != cfg.InitialSpeed+cfg.SpeedIncrement {
+		t.Errorf("Expected speed %f but got %f", cfg.InitialSpeed+cfg.SpeedIncrement, engine.Speed())
+	}
+}
+
+func TestEngineMagicJewel(t *testing.T) {
+	magic := []int{doric.MagicJewel, doric.MagicJewel, doric.MagicJewel}
+	well := parseWell(t, `
+		......
+		......
+		......
+		...2..
+		213242
+	`)
+	engine := newEngine(t, defaultConfig(), well, [][]int{magic, {4, 5, 6}})
+	engine.Start()
+	engine.Tick()
+	engine.Tick()
+
+	events := withKinds(engine.Tick(), doric.KindMagicJewel, doric.KindRenewed)
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events but got %d", len(events))
+	}
+	magicEvent, ok := events[0].(doric.EventMagicJewel)
+	if !ok {
+		t.Fatalf("Expected an EventMagicJewel but got %T", events[0])
+	}
+	if magicEvent.Color != 2 {
+		t.Errorf("Expected color 2 to be destroyed, got %d", magicEvent.Color)
+	}
+	if magicEvent.Removed != 4 {
+		t.Errorf("Expected 4 removed tiles but got %d", magicEvent.Removed)
+	}
+	expectedWell := parseWell(t, `
+		......
+		......
+		......
+		......
+		.13.4.
+	`)
+	renewed := events[1].(doric.EventRenewed)
+	if !reflect.DeepEqual(expectedWell, renewed.Well) {
+		t.Errorf("Expected well %v but got %v", expectedWell, renewed.Well)
+	}
+}
+
+func TestEngineScore(t *testing.T) {
+	well := parseWell(t, `
+		......
+		......
+		.22.11
+	`)
+	engine := newEngine(t, defaultConfig(), well, [][]int{{1, 2, 3}, {4, 5, 6}})
+	engine.Start()
+	engine.Step(doric.CommandDown)
+	engine.Step(doric.CommandDown)
+
+	events := withKinds(engine.Tick(), doric.KindScored, doric.KindRenewed)
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events but got %d", len(events))
+	}
+	expected := []struct {
+		points int
+		score  int
+	}{
+		{points: 90, score: 92},
+		{points: 180, score: 272},
+	}
+	for i, exp := range expected {
+		scored := events[i].(doric.EventScored)
+		if scored.Points != exp.points {
+			t.Errorf("Expected %d points but got %d", exp.points, scored.Points)
+		}
+		if scored.Score != exp.score {
+			t.Errorf("Expected score %d but got %d", exp.score, scored.Score)
+		}
+	}
+	if renewed := events[2].(doric.EventRenewed); renewed.Score != 272 {
+		t.Errorf("Expected score %d but got %d", 272, renewed.Score)
+	}
+}
+
+func TestEngineChainDetail(t *testing.T) {
+	t.Run("Must report matches and moves of every step of the chain", func(t *testing.T) {
+		well := parseWell(t, `
+			......
+			......
+			.22.11
+		`)
+		engine := newEngine(t, defaultConfig(), well, [][]int{{1, 2, 3}, {4, 5, 6}})
+		engine.Start()
+		engine.Step(doric.CommandDown)
+		engine.Step(doric.CommandDown)
+
+		events := withKinds(engine.Tick(), doric.KindScored, doric.KindSettled)
+		if len(events) != 4 {
+			t.Fatalf("Expected 4 events but got %d", len(events))
+		}
+		expectedMatches := [][]doric.Match{
+			{{Direction: doric.DirectionHorizontal, Length: 3, Color: 1, Cells: []doric.Position{{X: 3, Y: 2}, {X: 4, Y: 2}, {X: 5, Y: 2}}}},
+			{{Direction: doric.DirectionHorizontal, Length: 3, Color: 2, Cells: []doric.Position{{X: 1, Y: 2}, {X: 2, Y: 2}, {X: 3, Y: 2}}}},
+		}
+		expectedMoves := [][]doric.Move{
+			{
+				{From: doric.Position{X: 3, Y: 1}, To: doric.Position{X: 3, Y: 2}, Tile: 2},
+				{From: doric.Position{X: 3, Y: 0}, To: doric.Position{X: 3, Y: 1}, Tile: 3},
+			},
+			{
+				{From: doric.Position{X: 3, Y: 1}, To: doric.Position{X: 3, Y: 2}, Tile: 3},
+			},
+		}
+		for i := range expectedMatches {
+			if scored := events[i*2].(doric.EventScored); !reflect.DeepEqual(scored.Matches, expectedMatches[i]) {
+				t.Errorf("Expected matches %v but got %v", expectedMatches[i], scored.Matches)
+			}
+			if settled := events[i*2+1].(doric.EventSettled); !reflect.DeepEqual(settled.Moves, expectedMoves[i]) {
+				t.Errorf("Expected moves %v but got %v", expectedMoves[i], settled.Moves)
+			}
+		}
+	})
+
+	t.Run("Must count tiles shared by crossing matches only once", func(t *testing.T) {
+		well := parseWell(t, `
+			......
+			......
+			..1.1.
+		`)
+		engine := newEngine(t, defaultConfig(), well, [][]int{{1, 1, 1}, {4, 5, 6}})
+		engine.Start()
+		engine.Step(doric.CommandDown)
+		engine.Step(doric.CommandDown)
+
+		events := withKinds(engine.Tick(), doric.KindScored)
+		scored := events[0].(doric.EventScored)
+		if scored.Removed != 5 {
+			t.Errorf("Expected 5 removed tiles but got %d", scored.Removed)
+		}
+		expected := []doric.Match{
+			{Direction: doric.DirectionHorizontal, Length: 3, Color: 1, Cells: []doric.Position{{X: 2, Y: 2}, {X: 3, Y: 2}, {X: 4, Y: 2}}},
+			{Direction: doric.DirectionVertical, Length: 3, Color: 1, Cells: []doric.Position{{X: 3, Y: 0}, {X: 3, Y: 1}, {X: 3, Y: 2}}},
+		}
+		if !reflect.DeepEqual(scored.Matches, expected) {
+			t.Errorf("Expected matches %v but got %v", expected, scored.Matches)
+		}
+	})
+}
+
+func TestEngineRotate(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.ColumnLength = 4
+	engine := newEngine(t, cfg, doric.NewWell(doric.StandardWidth, doric.StandardHeight), [][]int{{1, 2, 3, 4}})
+	engine.Start()
+
+	events := engine.Step(doric.CommandRotate)
+	if upd := events[0].(doric.EventUpdated); !reflect.DeepEqual(upd.Column.Tileset, []int{4, 1, 2, 3}) {
+		t.Errorf("Expected tileset %v but got %v", []int{4, 1, 2, 3}, upd.Column.Tileset)
+	}
+	engine.Step(doric.CommandRotateReverse)
+	events = engine.Step(doric.CommandRotateReverse)
+	if upd := events[0].(doric.EventUpdated); !reflect.DeepEqual(upd.Column.Tileset, []int{2, 3, 4, 1}) {
+		t.Errorf("Expected tileset %v but got %v", []int{2, 3, 4, 1}, upd.Column.Tileset)
+	}
+}
+
+func TestEngineDrop(t *testing.T) {
+	well := parseWell(t, `
+		......
+		......
+		......
+		.22.11
+	`)
+	engine := newEngine(t, defaultConfig(), well, [][]int{{1, 2, 3}, {4, 5, 6}})
+	engine.Start()
+
+	events := withKinds(engine.Step(doric.CommandDrop), doric.KindScored, doric.KindRenewed)
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events but got %d", len(events))
+	}
+	for i, ev := range events[:2] {
+		scored, ok := ev.(doric.EventScored)
+		if !ok {
+			t.Fatalf("Expected an EventScored but got %T", ev)
+		}
+		if scored.Combo != i+1 {
+			t.Errorf("Expected combo %d but got %d", i+1, scored.Combo)
+		}
+	}
+	renewed, ok := events[2].(doric.EventRenewed)
+	if !ok {
+		t.Fatalf("Expected an EventRenewed but got %T", events[2])
+	}
+	if !reflect.DeepEqual(renewed.Column.Tileset, []int{4, 5, 6}) {
+		t.Errorf("Expected a new column to enter the well, got %v", renewed.Column)
+	}
+	if renewed.Score != 273 {
+		t.Errorf("Expected score %d but got %d", 273, renewed.Score)
+	}
+}
+
+func TestEngineNumColors(t *testing.T) {
+	tests := []struct {
+		name      string
+		numColors int
+		expected  int
+	}{
+		{
+			name:      "Must use default number of colors if not set",
+			numColors: 0,
+			expected:  doric.DefaultNumColors,
+		},
+		{
+			name:      "Must pass configured number of colors to builder",
+			numColors: 4,
+			expected:  4,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := defaultConfig()
+			cfg.NumColors = test.numColors
+			received := 0
+			builder := func(n, length int) []int {
+				received = n
+				return []int{1, 2, 3}
+			}
+			if _, err := doric.NewEngine(doric.NewWell(doric.StandardWidth, doric.StandardHeight), builder, cfg); err != nil {
+				t.Fatalf(err.Error())
+			}
+			if received != test.expected {
+				t.Errorf("Expected builder to receive %d colors but got %d", test.expected, received)
+			}
+		})
+	}
+}
+
+func TestEngineColumnLengthAndMinMatch(t *testing.T) {
+	tests := []struct {
+		name            string
+		well            doric.Well
+		expectedRemoved int
+	}{
+		{
+			name: "Must remove lines as long as MinMatch",
+			well: parseWell(t, `
+				......
+				......
+				......
+				......
+				222...
+			`),
+			expectedRemoved: 4,
+		},
+		{
+			name: "Must not remove lines shorter than MinMatch",
+			well: parseWell(t, `
+				......
+				......
+				......
+				......
+				.22...
+			`),
+			expectedRemoved: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := defaultConfig()
+			cfg.ColumnLength = 4
+			cfg.MinMatch = 4
+			engine := newEngine(t, cfg, test.well, [][]int{{2, 3, 4, 5}, {4, 5, 6, 1}})
+			events := engine.Start()
+			if renewed := events[0].(doric.EventRenewed); len(renewed.Column.Tileset) != 4 {
+				t.Errorf("Expected a column with 4 tiles, got %v", renewed.Column.Tileset)
+			}
+
+			removed := 0
+			for _, ev := range engine.Step(doric.CommandDrop) {
+				if scored, ok := ev.(doric.EventScored); ok {
+					removed += scored.Removed
+				}
+			}
+			if removed != test.expectedRemoved {
+				t.Errorf("Expected %d removed tiles but got %d", test.expectedRemoved, removed)
+			}
+		})
+	}
+}
+
+func TestEngineInvalidTileset(t *testing.T) {
+	well := doric.NewWell(doric.StandardWidth, doric.StandardHeight)
+
+	t.Run("Must return error if the first tileset is not valid", func(t *testing.T) {
+		if _, err := doric.NewEngine(well, doric.NewSequenceBuilder([]int{}), defaultConfig()); err == nil {
+			t.Errorf("Expected error creating an engine whose builder returns empty tilesets")
+		}
+	})
+
+	t.Run("Must end the game if a later tileset is not valid", func(t *testing.T) {
+		engine := newEngine(t, defaultConfig(), well, [][]int{{1, 2, 3}, {1, 2}})
+		events := engine.Start()
+		over, ok := events[len(events)-1].(doric.EventGameOver)
+		if !ok {
+			t.Fatalf("Expected an EventGameOver but got %v", events)
+		}
+		if over.Reason != doric.ReasonInvalidTileset || over.Err == nil {
+			t.Errorf("Expected game to end because of an invalid tileset, got %v (%v)", over.Reason, over.Err)
+		}
+		if events := engine.Step(doric.CommandRotate); events != nil {
+			t.Errorf("Expected no events after the game is over, got %v", events)
+		}
+	})
+}
+
+func TestEngineMoveTo(t *testing.T) {
+	well := parseWell(t, `
+		......
+		......
+		1.....
+	`)
+	tests := []struct {
+		name      string
+		command   doric.Command
+		expectedX int
+	}{
+		{
+			name:      "Must move to the passed column",
+			command:   doric.MoveTo(5),
+			expectedX: 5,
+		},
+		{
+			name:      "Must move as far as possible if there are tiles in the way",
+			command:   doric.MoveTo(0),
+			expectedX: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			engine := newEngine(t, defaultConfig(), well, [][]int{{1, 2, 3}})
+			engine.Start()
+			events := engine.Step(test.command)
+			upd, ok := events[0].(doric.EventUpdated)
+			if !ok {
+				t.Fatalf("Expected an EventUpdated but got %T", events[0])
+			}
+			if upd.Column.X != test.expectedX {
+				t.Errorf("Expected column to be at %d but got %d", test.expectedX, upd.Column.X)
+			}
+		})
+	}
+}
+
+func TestEngineRejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  doric.Command
+		expected doric.RejectionReason
+	}{
+		{
+			name:     "Must reject unknown commands",
+			command:  doric.Command(200),
+			expected: doric.RejectInvalid,
+		},
+		{
+			name:     "Must reject moving out of the well",
+			command:  doric.MoveTo(doric.StandardWidth),
+			expected: doric.RejectOutOfBounds,
+		},
+		{
+			name:     "Must reject moving left of the well",
+			command:  doric.MoveTo(-1),
+			expected: doric.RejectOutOfBounds,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			engine := newEngine(t, defaultConfig(), doric.NewWell(doric.StandardWidth, doric.StandardHeight), [][]int{{1, 2, 3}})
+			engine.Start()
+			events := engine.Step(test.command)
+			rej, ok := events[0].(doric.EventRejected)
+			if !ok {
+				t.Fatalf("Expected an EventRejected but got %T", events[0])
+			}
+			if rej.Reason != test.expected {
+				t.Errorf("Expected reason %s but got %s", test.expected, rej.Reason)
+			}
+		})
+	}
+}
+
+func TestEngineLockDelay(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.LockDelay = 2
+	cfg.LockResets = 1
+
+	t.Run("Must lock column after lock delay", func(t *testing.T) {
+		engine := newEngine(t, cfg, doric.NewWell(doric.StandardWidth, 4), [][]int{{1, 2, 3}, {4, 5, 6}})
+		engine.Start()
+		engine.Tick()
+		engine.Tick()
+		engine.Tick()
+
+		events := engine.Tick()
+		if len(events) != 1 || events[0].Kind() != doric.KindTouchedDown {
+			t.Fatalf("Expected column to touch down, got %v", events)
+		}
+		if events := engine.Tick(); len(events) != 0 {
+			t.Errorf("Expected column not to be locked before the lock delay expires, got %v", events)
+		}
+		events = engine.Tick()
+		if !hasKind(events, doric.KindRenewed) {
+			t.Errorf("Expected column to be locked after the lock delay expires, got %v", events)
+		}
+	})
+
+	t.Run("Must restart lock delay when moving up to the resets limit", func(t *testing.T) {
+		engine := newEngine(t, cfg, doric.NewWell(doric.StandardWidth, 4), [][]int{{1, 2, 3}, {4, 5, 6}})
+		engine.Start()
+		for i := 0; i < 5; i++ {
+			engine.Tick()
+		}
+		engine.Step(doric.CommandLeft)
+		if events := engine.Tick(); len(events) != 0 {
+			t.Errorf("Expected lock delay to be restarted, got %v", events)
+		}
+		engine.Step(doric.CommandRight)
+		events := engine.Tick()
+		if !hasKind(events, doric.KindRenewed) {
+			t.Errorf("Expected column to be locked once the resets limit is reached, got %v", events)
+		}
+	})
+
+	t.Run("Must fall again if moved over an empty cell", func(t *testing.T) {
+		well := parseWell(t, `
+			......
+			......
+			...1..
+		`)
+		engine := newEngine(t, cfg, well, [][]int{{1, 2, 3}, {4, 5, 6}})
+		engine.Start()
+		engine.Tick()
+		engine.Tick()
+		engine.Step(doric.CommandLeft)
+		events := engine.Tick()
+		if upd, ok := events[0].(doric.EventUpdated); !ok || upd.Column.Y != 2 {
+			t.Errorf("Expected column to fall again, got %v", events)
+		}
+	})
+}
+
+func TestEngineClearAndSpawnDelays(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.ClearDelay = 2
+	cfg.SpawnDelay = 1
+	well := parseWell(t, `
+		......
+		......
+		.22.11
+	`)
+	engine := newEngine(t, cfg, well, [][]int{{1, 2, 3}, {4, 5, 6}})
+	engine.Start()
+	engine.Step(doric.CommandDown)
+	engine.Step(doric.CommandDown)
+
+	expected := [][]doric.EventKind{
+		{doric.KindScored, doric.KindClearStarted},
+		{},
+		{doric.KindSettled, doric.KindScored, doric.KindClearStarted},
+		{},
+		{doric.KindSettled},
+		{doric.KindRenewed},
+	}
+	for i, kinds := range expected {
+		events := engine.Tick()
+		if len(events) != len(kinds) {
+			t.Fatalf("Tick %d: expected %d events but got %v", i, len(kinds), events)
+		}
+		for j, kind := range kinds {
+			if events[j].Kind() != kind {
+				t.Errorf("Tick %d: expected event %d to be %s but got %s", i, j, kind, events[j].Kind())
+			}
+		}
+		if i == 1 {
+			events := engine.Step(doric.CommandLeft)
+			if rejected, ok := events[0].(doric.EventRejected); !ok || rejected.Reason != doric.RejectNotFalling {
+				t.Errorf("Expected command to be rejected while clearing, got %v", events)
+			}
+		}
+	}
+}
+
+func TestEngineStartLevel(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.NumberTilesForNextLevel = 3
+	cfg.StartLevel = 3
+	well := parseWell(t, `
+		......
+		......
+		.22.11
+	`)
+	engine := newEngine(t, cfg, well, [][]int{{1, 2, 3}, {4, 5, 6}})
+	if engine.Speed() != 7 {
+		t.Errorf("Expected speed %v but got %v", 7, engine.Speed())
+	}
+	engine.Start()
+	engine.Step(doric.CommandDown)
+	engine.Step(doric.CommandDown)
+
+	events := withKinds(engine.Tick(), doric.KindScored)
+	for i, level := range []int{4, 5} {
+		if scored := events[i].(doric.EventScored); scored.Level != level {
+			t.Errorf("Expected level %d but got %d", level, scored.Level)
+		}
+	}
+	if state := engine.State(); state.TotalRemoved != 6 {
+		t.Errorf("Expected 6 removed tiles but got %d", state.TotalRemoved)
+	}
+}
+
+func TestEnginePractice(t *testing.T) {
+	well := parseWell(t, `
+		......
+		......
+		......
+		......
+		1...2.
+	`)
+	tilesets := [][]int{{1, 2, 3}, {4, 5, 6}, {2, 3, 4}}
+
+	t.Run("Must restart from the initial position with the same sequence of columns", func(t *testing.T) {
+		cfg := defaultConfig()
+		cfg.Practice = true
+		engine := newEngine(t, cfg, well, tilesets)
+		start := withKinds(engine.Start(), doric.KindRenewed)[0].(doric.EventRenewed)
+		engine.Step(doric.CommandDrop)
+		engine.Step(doric.CommandLeft)
+
+		restarted := withKinds(engine.Step(doric.CommandRestart), doric.KindRenewed)
+		if len(restarted) != 1 {
+			t.Fatalf("Expected game to be restarted, got %v", restarted)
+		}
+		renewed := restarted[0].(doric.EventRenewed)
+		if !reflect.DeepEqual(renewed.Well, start.Well) || !reflect.DeepEqual(renewed.Column, start.Column) ||
+			!reflect.DeepEqual(renewed.NextTileset, start.NextTileset) || renewed.Score != 0 {
+			t.Errorf("Expected game to be back to %v, got %v", start, renewed)
+		}
+
+		renewed = withKinds(engine.Step(doric.CommandDrop), doric.KindRenewed)[0].(doric.EventRenewed)
+		if !reflect.DeepEqual(renewed.NextTileset, []int{2, 3, 4}) {
+			t.Errorf("Expected the same sequence of columns after restarting, got %v", renewed.NextTileset)
+		}
+		if state := engine.State(); state.Drawn != 3 {
+			t.Errorf("Expected builder to be called 3 times, got %d", state.Drawn)
+		}
+	})
+
+	t.Run("Must reject restarting outside practice mode", func(t *testing.T) {
+		engine := newEngine(t, defaultConfig(), well, tilesets)
+		engine.Start()
+		events := engine.Step(doric.CommandRestart)
+		if rejected, ok := events[0].(doric.EventRejected); !ok || rejected.Reason != doric.RejectNotPractice {
+			t.Errorf("Expected restart to be rejected, got %v", events)
+		}
+	})
+}
+
+// withKinds returns only the events of the passed kinds
+func withKinds(events []doric.Event, kinds ...doric.EventKind) []doric.Event {
+	var filtered []doric.Event
+	for _, ev := range events {
+		for _, kind := range kinds {
+			if ev.Kind() == kind {
+				filtered = append(filtered, ev)
+			}
+		}
+	}
+	return filtered
+}
+
+// hasKind returns true if there is an event of the passed kind among the passed ones
+func hasKind(events []doric.Event, kind doric.EventKind) bool {
+	for _, ev := range events {
+		if ev.Kind() == kind {
+			return true
+		}
+	}
+	return false
+}
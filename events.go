@@ -1,22 +1,287 @@
 package doric
 
-// EventUpdated is sent as a response to a current column movement
+import "time"
+
+// Possible reasons for a game to end
+const (
+	// No more columns can enter the well
+	ReasonToppedOut GameOverReason = iota
+	// The player sent CommandQuit
+	ReasonQuit
+	// The game was cancelled from outside, e. g. its context was cancelled
+	ReasonCancelled
+	// The tileset builder returned a tileset which is not valid for the game configuration
+	ReasonInvalidTileset
+)
+
+// GameOverReason tells why a game ended
+type GameOverReason int
+
+func (r GameOverReason) String() string {
+	switch r {
+	case ReasonToppedOut:
+		return "topped out"
+	case ReasonQuit:
+		return "quit"
+	case ReasonCancelled:
+		return "cancelled"
+	case ReasonInvalidTileset:
+		return "invalid tileset"
+	}
+	return "unknown"
+}
+
+// Kinds of events sent by a game
+const (
+	KindUpdated EventKind = iota
+	KindScored
+	KindRenewed
+	KindMagicJewel
+	KindGameOver
+	KindSnapshot
+	KindRejected
+	KindTouchedDown
+	KindClearStarted
+	KindSettled
+)
+
+// EventKind identifies the type of an event
+type EventKind int
+
+func (k EventKind) String() string {
+	switch k {
+	case KindUpdated:
+		return "updated"
+	case KindScored:
+		return "scored"
+	case KindRenewed:
+		return "renewed"
+	case KindMagicJewel:
+		return "magic jewel"
+	case KindGameOver:
+		return "game over"
+	case KindSnapshot:
+		return "snapshot"
+	case KindRejected:
+		return "rejected"
+	case KindTouchedDown:
+		return "touched down"
+	case KindClearStarted:
+		return "clear started"
+	case KindSettled:
+		return "settled"
+	}
+	return "unknown"
+}
+
+// Event is implemented by all events sent by a game
+type Event interface {
+	// Kind returns the type of the event, so it can be identified without a type switch
+	Kind() EventKind
+	// Sequence returns the position of the event in the sequence of events sent by the game,
+	// starting from 1
+	Sequence() uint64
+	// GameTick returns the number of game ticks elapsed when the event was produced
+	GameTick() int
+}
+
+// EventHeader holds the data common to all events
+type EventHeader struct {
+	Seq  uint64 `json:"seq"`
+	Tick int    `json:"tick"`
+}
+
+// Sequence returns the position of the event in the sequence of events sent by the game
+func (h EventHeader) Sequence() uint64 {
+	return h.Seq
+}
+
+// GameTick returns the number of game ticks elapsed when the event was produced
+func (h EventHeader) GameTick() int {
+	return h.Tick
+}
+
+// Possible reasons for a command to be rejected
+const (
+	// The command is not a known one or carries a wrong payload
+	RejectInvalid RejectionReason = iota
+	// The game is paused or waiting, so the column cannot be moved
+	RejectPaused
+	// The command payload points outside the well
+	RejectOutOfBounds
+	// There is no column falling, as tiles are being removed or a new column is about to enter the well
+	RejectNotFalling
+	// The command is only available in practice mode
+	RejectNotPractice
+)
+
+// RejectionReason tells why a command was rejected
+type RejectionReason int
+
+func (r RejectionReason) String() string {
+	switch r {
+	case RejectInvalid:
+		return "invalid"
+	case RejectPaused:
+		return "paused"
+	case RejectOutOfBounds:
+		return "out of bounds"
+	case RejectNotFalling:
+		return "not falling"
+	case RejectNotPractice:
+		return "not practice"
+	}
+	return "unknown"
+}
+
+// EventUpdated is sent as a response to a current column movement.
+// LandingY is the vertical position the column would land at if it kept falling.
 type EventUpdated struct {
-	Column Column
+	EventHeader
+	Column   Column `json:"column"`
+	LandingY int    `json:"landingY"`
+}
+
+// Kind returns KindUpdated
+func (e EventUpdated) Kind() EventKind {
+	return KindUpdated
 }
 
 // EventScored is sent when the three or more tiles of the same color are aligned in the well,
-// thus scoring points for the player
+// thus scoring points for the player.
+// Points are the ones awarded in this step of the chain, while Score is the running score of the game.
+// Matches holds the lines of tiles removed, so Removed may be lower than the sum of their lengths
+// if some of them cross.
 type EventScored struct {
-	Well    Well
-	Combo   int
-	Removed int
-	Level   int
+	EventHeader
+	Well    Well    `json:"well"`
+	Combo   int     `json:"combo"`
+	Removed int     `json:"removed"`
+	Matches []Match `json:"matches"`
+	Level   int     `json:"level"`
+	Points  int     `json:"points"`
+	Score   int     `json:"score"`
+}
+
+// Kind returns KindScored
+func (e EventScored) Kind() EventKind {
+	return KindScored
 }
 
-// EventRenewed is sent when the current and next columns are renewed
+// EventRenewed is sent when the current and next columns are renewed.
+// LandingY is the vertical position the new column would land at if it kept falling.
 type EventRenewed struct {
-	Well        Well
-	Column      Column
-	NextTileset [3]int
+	EventHeader
+	Well        Well   `json:"well"`
+	Column      Column `json:"column"`
+	NextTileset []int  `json:"nextTileset"`
+	LandingY    int    `json:"landingY"`
+	Score       int    `json:"score"`
+}
+
+// Kind returns KindRenewed
+func (e EventRenewed) Kind() EventKind {
+	return KindRenewed
+}
+
+// EventMagicJewel is sent when a magic column lands, destroying all tiles
+// in the well with the same color as the one underneath it
+type EventMagicJewel struct {
+	EventHeader
+	Well    Well `json:"well"`
+	Color   int  `json:"color"`
+	Removed int  `json:"removed"`
+	Level   int  `json:"level"`
+	Points  int  `json:"points"`
+	Score   int  `json:"score"`
+}
+
+// Kind returns KindMagicJewel
+func (e EventMagicJewel) Kind() EventKind {
+	return KindMagicJewel
+}
+
+// EventGameOver is the last event sent before a game ends, holding its final statistics.
+// Elapsed is the time played, not including the time the game was paused.
+// If the game was cancelled or the builder returned an invalid tileset, Err holds the cause.
+// Dropped is the number of events discarded because of the drop policy set in the game configuration.
+type EventGameOver struct {
+	EventHeader
+	Reason       GameOverReason `json:"reason"`
+	Well         Well           `json:"well"`
+	Level        int            `json:"level"`
+	Score        int            `json:"score"`
+	TotalRemoved int            `json:"totalRemoved"`
+	MaxCombo     int            `json:"maxCombo"`
+	Elapsed      time.Duration  `json:"elapsed"`
+	Err          error          `json:"-"`
+	Dropped      uint64         `json:"dropped"`
+}
+
+// Kind returns KindGameOver
+func (e EventGameOver) Kind() EventKind {
+	return KindGameOver
+}
+
+// EventSnapshot is sent as a response to CommandSnapshot, holding the current state of the game
+type EventSnapshot struct {
+	EventHeader
+	State GameState `json:"state"`
+}
+
+// Kind returns KindSnapshot
+func (e EventSnapshot) Kind() EventKind {
+	return KindSnapshot
+}
+
+// EventRejected is sent instead of EventUpdated when a command cannot be executed
+type EventRejected struct {
+	EventHeader
+	Command Command         `json:"command"`
+	Reason  RejectionReason `json:"reason"`
+}
+
+// Kind returns KindRejected
+func (e EventRejected) Kind() EventKind {
+	return KindRejected
+}
+
+// EventTouchedDown is sent when the current column cannot fall further but, as a lock delay is set
+// in the game configuration, it can still be moved or rotated for LockDelay ticks before being locked
+type EventTouchedDown struct {
+	EventHeader
+	Column    Column `json:"column"`
+	LockDelay int    `json:"lockDelay"`
+}
+
+// Kind returns KindTouchedDown
+func (e EventTouchedDown) Kind() EventKind {
+	return KindTouchedDown
+}
+
+// EventClearStarted is sent after tiles are marked to be removed, either by a step of a chain (Combo being
+// its number) or by a magic column (Combo being 0). Marked tiles will be removed after Delay ticks,
+// so front-ends can animate them meanwhile.
+type EventClearStarted struct {
+	EventHeader
+	Combo int `json:"combo"`
+	Delay int `json:"delay"`
+}
+
+// Kind returns KindClearStarted
+func (e EventClearStarted) Kind() EventKind {
+	return KindClearStarted
+}
+
+// EventSettled is sent after marked tiles are removed and the remaining ones fall to fill the gaps.
+// Moves holds every tile which fell, ordered by column from the bottom up.
+type EventSettled struct {
+	EventHeader
+	Well  Well   `json:"well"`
+	Moves []Move `json:"moves"`
+}
+
+// Kind returns KindSettled
+func (e EventSettled) Kind() EventKind {
+	return KindSettled
 }
@@ -2,7 +2,6 @@ package doric_test
 
 import (
 	"log"
-	"math/rand"
 	"time"
 
 	"github.com/svera/doric"
@@ -15,20 +14,13 @@ func Example() {
 		SpeedIncrement:          0.25,
 		MaxSpeed:                13,
 	}
-	command := make(chan int)
+	command := make(chan doric.Command)
 	well := doric.NewWell(doric.StandardWidth, doric.StandardHeight)
-	factory := func(n int) [3]int {
-		source := rand.NewSource(time.Now().UnixNano())
-		r := rand.New(source)
-		return [3]int{
-			r.Intn(n) + 1,
-			r.Intn(n) + 1,
-			r.Intn(n) + 1,
-		}
-	}
+	// Builders created with the same seed return the same sequence of columns
+	builder := doric.NewRandomBuilder(time.Now().UnixNano())
 
 	// Start the game and return game events in the events channel
-	events, err := doric.Play(well, factory, cfg, command)
+	events, err := doric.Play(well, builder, cfg, command)
 	if err != nil {
 		log.Fatalf("%s\n", err.Error())
 	}
@@ -57,6 +49,8 @@ func Example() {
 				// Do whatever
 			case doric.EventRenewed:
 				// Do whatever
+			case doric.EventGameOver:
+				// Show final statistics
 			}
 		case <-tick:
 			// Update screen, send commands to game through the
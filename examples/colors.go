@@ -2,6 +2,16 @@ package main
 
 import (
 	tl "github.com/JoelOtter/termloop"
+	"github.com/svera/doric"
 )
 
-var colors = []tl.Attr{tl.ColorBlack, tl.ColorCyan, tl.ColorGreen, tl.ColorMagenta, tl.ColorRed, tl.ColorYellow, tl.ColorBlue}
+var colors = map[int]tl.Attr{
+	doric.Empty:      tl.ColorBlack,
+	1:                tl.ColorCyan,
+	2:                tl.ColorGreen,
+	3:                tl.ColorMagenta,
+	4:                tl.ColorRed,
+	5:                tl.ColorYellow,
+	6:                tl.ColorBlue,
+	doric.MagicJewel: tl.ColorWhite,
+}
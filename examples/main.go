@@ -12,13 +12,14 @@ import (
 )
 
 const (
-	offsetX       = 32
-	offsetY       = 5
-	pointsPerTile = 10
+	offsetX = 32
+	offsetY = 5
+	// Probability of a new column being a magic one
+	magicJewelProbability = 0.02
 )
 
 func main() {
-	commands := make(chan int)
+	commands := make(chan doric.Command)
 	app := tl.NewGame()
 	app.Screen().SetFps(60)
 
@@ -37,25 +38,21 @@ func setUpMainLevel(mainLevel *tl.BaseLevel, entities []tl.Drawable) {
 	}
 }
 
-func startGameLogic(commands chan int) []tl.Drawable {
+func startGameLogic(commands chan doric.Command) []tl.Drawable {
 	well := doric.NewWell(doric.StandardWidth, doric.StandardHeight)
 	cfg := doric.Config{
 		NumberTilesForNextLevel: 10,
 		InitialSpeed:            0.5,
 		SpeedIncrement:          0.25,
 		MaxSpeed:                13,
+		// Only the last position of the column matters when drawing it
+		DropPolicy: doric.PolicyCoalesceUpdates,
 	}
 
-	factory := func(n int) [3]int {
-		source := rand.NewSource(time.Now().UnixNano())
-		r := rand.New(source)
-		return [3]int{
-			r.Intn(n) + 1,
-			r.Intn(n) + 1,
-			r.Intn(n) + 1,
-		}
-	}
-	events, err := doric.Play(well, factory, cfg, commands)
+	// Colors and magic jewels are drawn from different seeds, so they are not correlated
+	seeds := rand.New(rand.NewSource(time.Now().UnixNano()))
+	builder := doric.WithMagicJewel(doric.NewRandomBuilder(seeds.Int63()), magicJewelProbability, seeds.Int63())
+	events, err := doric.Play(well, builder, cfg, commands)
 	if err != nil {
 		log.Fatalf("%s\n", err.Error())
 	}
@@ -73,7 +70,6 @@ func startGameLogic(commands chan int) []tl.Drawable {
 	level := tl.NewText(offsetX+16, offsetY+1, fmt.Sprintf("Level: %d", 1), tl.ColorWhite, tl.ColorBlack)
 
 	go func() {
-		points := 0
 		defer func() {
 			playerEntity.Finished = true
 			close(commands)
@@ -82,8 +78,12 @@ func startGameLogic(commands chan int) []tl.Drawable {
 			switch t := ev.(type) {
 			case doric.EventScored:
 				mux.Lock()
-				points += t.Removed * t.Combo * pointsPerTile
-				score.SetText(fmt.Sprintf("Score: %d", points))
+				score.SetText(fmt.Sprintf("Score: %d", t.Score))
+				level.SetText(fmt.Sprintf("Level: %d", t.Level))
+				mux.Unlock()
+			case doric.EventMagicJewel:
+				mux.Lock()
+				score.SetText(fmt.Sprintf("Score: %d", t.Score))
 				level.SetText(fmt.Sprintf("Level: %d", t.Level))
 				mux.Unlock()
 			case doric.EventUpdated:
@@ -95,6 +95,7 @@ func startGameLogic(commands chan int) []tl.Drawable {
 				wellEntity.Well = t.Well
 				playerEntity.Current = &t.Column
 				nextColumnEntity.Column = t.NextTileset
+				score.SetText(fmt.Sprintf("Score: %d", t.Score))
 				mux.Unlock()
 			}
 		}
@@ -9,14 +9,14 @@ import (
 // Next is an entity used to show next column on screen
 type Next struct {
 	*tl.Entity
-	Column  [3]int
+	Column  []int
 	offsetX int
 	offsetY int
 	mux     sync.Locker
 }
 
 // NewNext returns a new Next instance
-func NewNext(p [3]int, offsetX, offsetY int, mux sync.Locker) *Next {
+func NewNext(p []int, offsetX, offsetY int, mux sync.Locker) *Next {
 	return &Next{
 		Entity:  tl.NewEntity(offsetX, offsetY, 1, 3),
 		Column:  p,
@@ -11,7 +11,7 @@ import (
 type Player struct {
 	*tl.Entity
 	Current  *doric.Column
-	Command  chan<- int
+	Command  chan<- doric.Command
 	offsetX  int
 	offsetY  int
 	message  tl.Drawable
@@ -21,7 +21,7 @@ type Player struct {
 }
 
 // NewPlayer returns a new Player instance
-func NewPlayer(c *doric.Column, command chan<- int, message tl.Drawable, offsetX, offsetY int, mux sync.Locker) *Player {
+func NewPlayer(c *doric.Column, command chan<- doric.Command, message tl.Drawable, offsetX, offsetY int, mux sync.Locker) *Player {
 	return &Player{
 		Current: c,
 		Command: command,
@@ -76,8 +76,12 @@ func (p *Player) Tick(event tl.Event) {
 			p.Command <- doric.CommandLeft
 		case tl.KeyArrowDown:
 			p.Command <- doric.CommandDown
+		case tl.KeySpace:
+			p.Command <- doric.CommandDrop
 		case tl.KeyTab:
 			p.Command <- doric.CommandRotate
+		case tl.KeyArrowUp:
+			p.Command <- doric.CommandRotateReverse
 		}
 
 		switch event.Ch {
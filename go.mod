@@ -1,6 +1,6 @@
 module github.com/svera/doric
 
-go 1.12
+go 1.13
 
 require (
 	github.com/JoelOtter/termloop v0.0.0-20191114154723-6c3a95a92fdd
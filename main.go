@@ -1,42 +1,39 @@
 package doric
 
 import (
+	"context"
 	"fmt"
 	"time"
 )
 
-// Possible commands coming from the player
-const (
-	// Move the current column left
-	CommandLeft = iota
-	// Move the current column right
-	CommandRight
-	// Move the current column down
-	CommandDown
-	// Rotate tiles in current column
-	CommandRotate
-	// Pause / unpause game (for player use)
-	CommandPauseSwitch
-	// Pause / unpause game (intended for internal use, e. g. stop game logic while play animation)
-	CommandWaitSwitch
-	// Quit game
-	CommandQuit
-)
-
 // Possible returned errors
 const (
 	errorNegativeNumberTilesForNextLevel = "NumberTilesForNextLevel must be equal or greater than 0"
 	errorLessEqualZeroInitialSpeed       = "InitialSpeed must be greater than 0"
 	errorNegativeSpeedIncrement          = "SpeedIncrement must be equal or greater than 0"
 	errorLessEqualZeroMaxSpeed           = "MaxSpeed must be greater than 0"
+	errorNumColorsOutOfRange             = "NumColors must be between 0 and MaxNumColors"
+	errorNegativeColumnLength            = "ColumnLength must be equal or greater than 0"
+	errorMinMatchOutOfRange              = "MinMatch must be 0 or greater than 1"
+	errorNegativeEventBuffer             = "EventBuffer must be equal or greater than 0"
+	errorNegativeLockDelay               = "LockDelay must be equal or greater than 0"
+	errorNegativeLockResets              = "LockResets must be equal or greater than 0"
+	errorNegativeClearDelay              = "ClearDelay must be equal or greater than 0"
+	errorNegativeSpawnDelay              = "SpawnDelay must be equal or greater than 0"
+	errorNegativeStartLevel              = "StartLevel must be equal or greater than 0"
+	errorUnknownDropPolicy               = "DropPolicy must be one of PolicyBlock, PolicyDropOldestUpdate or PolicyCoalesceUpdates"
+	errorInvalidBuiltTileset             = "Tileset %v returned by the builder is not valid: %v"
 )
 
 const nanosecond = 1000000000
 
+// cancelGracePeriod is how long a cancelled game waits for its final event to be read
+const cancelGracePeriod = 100 * time.Millisecond
+
 // Config holds different parameters related with the game
 type Config struct {
 	// How many tiles a player has to destroy to advance to the next level
-	// Must be equal or greater than zero.
+	// Must be equal or greater than zero. If 0, level never increases.
 	NumberTilesForNextLevel int
 	// InitialSpeed is the falling speed at the beginning of the game in cells/second.
 	// Must be greater than zero.
@@ -47,88 +44,299 @@ type Config struct {
 	// MaxSpeed is the maximum speed falling columns can reach
 	// Must be greater than zero.
 	MaxSpeed float64
-}
-
-type game struct {
-	well         Well
-	column       *Column
-	nextTileset  [3]int
-	level        int
-	paused       bool
-	wait         bool
-	totalRemoved int
-	cfg          Config
-	events       chan interface{}
-	speed        float64
-	ticker       *time.Ticker
-	build        TilesetBuilder
+	// Clock is the source of time used to make columns fall.
+	// If nil, wall-clock time is used.
+	Clock Clock
+	// Scorer calculates the points awarded to the player.
+	// If nil, DefaultScorer is used.
+	Scorer Scorer
+	// NumColors is the number of different colors tiles can have, which is passed to the
+	// tileset builder. Must be between 0 and MaxNumColors. If 0, DefaultNumColors is used.
+	NumColors int
+	// ColumnLength is the number of tiles composing each column.
+	// Must be equal or greater than zero. If 0, DefaultColumnLength is used.
+	ColumnLength int
+	// MinMatch is the minimum number of tiles of the same color which must be aligned to be removed.
+	// Must be 0 or greater than 1. If 0, DefaultMinMatch is used.
+	MinMatch int
+	// Recording, if not nil, gets the initial well and every command processed during the game,
+	// so it can be replayed later. It must not be read until the game is over.
+	// Games resumed from a snapshot are not recorded.
+	Recording *Recording
+	// EventBuffer is the number of events which can be waiting to be read, besides the one being offered
+	// to the reader, before the game waits or discards updates as the drop policy says. PolicyCoalesceUpdates
+	// ignores it, as it never keeps more than one update waiting. Must be equal or greater than zero.
+	EventBuffer int
+	// DropPolicy tells what to do when events are produced faster than they are read.
+	// By default, the game waits for events to be read.
+	DropPolicy DropPolicy
+	// LockDelay is the number of ticks a column which cannot fall further can still be moved or rotated
+	// before being locked in the well. Must be equal or greater than zero. If 0, columns are locked immediately.
+	LockDelay int
+	// LockResets is how many times moving or rotating a column which touched down restarts its lock delay.
+	// Must be equal or greater than zero.
+	LockResets int
+	// ClearDelay is the number of ticks marked tiles stay in the well before being removed, in every step of a chain,
+	// so front-ends can animate them. Must be equal or greater than zero.
+	ClearDelay int
+	// SpawnDelay is the number of ticks between the end of a chain and the entry of a new column in the well.
+	// Must be equal or greater than zero.
+	SpawnDelay int
+	// StartLevel is the level the game starts at. Speed is increased as many times as if the player had
+	// advanced to it, and the tiles needed to reach it count towards the next level, though not towards
+	// the total of removed tiles. Must be equal or greater than zero. If 0, the game starts at level 1.
+	// Resumed games keep the start level of the original game instead.
+	StartLevel int
+	// Practice enables practice mode, in which CommandRestart puts the game back in the position it started
+	// from: the well passed to Play (or the state passed to Resume), and the same sequence of columns,
+	// as many times as wanted. Combined with a pre-filled well and StartLevel, it allows to practise
+	// specific positions.
+	Practice bool
 }
 
 // Play starts the game loop in a separate thread, making columns fall to the bottom of the well at gradually quicker speeds
 // as level increases.
 // Game can be controlled sending command codes to the commands channel. Game updates are communicated as events in the returned
 // channel.
-// Game ends when no more new columns can enter the well, and this will be signaled with the closing of the
-// events channel.
-func Play(p Well, builder TilesetBuilder, cfg Config, commands <-chan int) (<-chan interface{}, error) {
-	game, err := newGame(p, builder, cfg)
+// Game ends when no more new columns can enter the well or the player quits. An EventGameOver with the final
+// statistics is sent, and then the events channel is closed.
+// Play is a thin wrapper which drives an Engine using a ticker running at the current game speed.
+func Play(p Well, builder TilesetBuilder, cfg Config, commands <-chan Command) (<-chan Event, error) {
+	return PlayContext(context.Background(), p, builder, cfg, commands)
+}
+
+// PlayContext works like Play, but the game also ends when the passed context is cancelled.
+// In that case, an EventGameOver with ReasonCancelled and the context error is sent if the events
+// channel is read shortly after, and the events channel is closed. The game goroutine
+// never outlives the context, even if events are not being read anymore.
+func PlayContext(ctx context.Context, p Well, builder TilesetBuilder, cfg Config, commands <-chan Command) (<-chan Event, error) {
+	engine, err := NewEngine(p, builder, cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	go func() {
-		defer func() {
-			close(game.events)
-			game.ticker.Stop()
-		}()
+	return run(ctx, engine, cfg, commands), nil
+}
+
+// Resume works like Play, but continues the game from the passed state, which can be obtained
+// sending CommandSnapshot to a running game. The builder must be created the same way as the one
+// used in the original game (e. g. with the same seed), so the same tilesets sequence is kept.
+func Resume(state GameState, builder TilesetBuilder, cfg Config, commands <-chan Command) (<-chan Event, error) {
+	return ResumeContext(context.Background(), state, builder, cfg, commands)
+}
+
+// ResumeContext works like Resume, but the game also ends when the passed context is cancelled,
+// as in PlayContext.
+func ResumeContext(ctx context.Context, state GameState, builder TilesetBuilder, cfg Config, commands <-chan Command) (<-chan Event, error) {
+	engine, err := NewEngineFromState(state, builder, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return run(ctx, engine, cfg, commands), nil
+}
+
+// PlayUntyped works like Play, but takes commands as plain integers and returns events in a channel
+// of empty interfaces, as Play did before commands and events had their own types.
+//
+// Deprecated: use Play instead.
+func PlayUntyped(p Well, builder TilesetBuilder, cfg Config, commands <-chan int) (<-chan interface{}, error) {
+	typed := make(chan Command)
+	events, err := Play(p, builder, cfg, typed)
+	if err != nil {
+		return nil, err
+	}
 
-		game.renewColumn()
+	done := make(chan struct{})
+	go func() {
 		for {
 			select {
-			case comm := <-commands:
-				if comm == CommandQuit {
+			case comm, open := <-commands:
+				if !open {
+					close(typed)
 					return
 				}
-				game.execute(comm)
-			case <-game.ticker.C:
-				if game.paused || game.wait {
-					continue
+				select {
+				case typed <- Command(comm):
+				case <-done:
+					return
 				}
-				if game.column.down(game.well) {
-					game.events <- EventUpdated{
-						Column: *game.column,
-					}
-					continue
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	untyped := make(chan interface{})
+	go func() {
+		defer func() {
+			close(untyped)
+			close(done)
+		}()
+		for ev := range events {
+			untyped <- ev
+		}
+	}()
+	return untyped, nil
+}
+
+// run starts the game loop driving the passed engine in a separate goroutine
+func run(ctx context.Context, engine *Engine, cfg Config, commands <-chan Command) <-chan Event {
+	events := make(chan Event)
+	queue := newEventQueue(cfg.EventBuffer, cfg.DropPolicy)
+	// The ticker is created before the game goroutine starts, so ticks of manual clocks
+	// are not missed if they are fired right after the game is started
+	clock := cfg.clock()
+	speed := engine.Speed()
+	ticker := clock.NewTicker(interval(speed))
+	go func() {
+		defer func() {
+			ticker.Stop()
+			close(events)
+		}()
+
+		// send queues the passed events and delivers them until the queue is not full, or until it is empty
+		// if wait is true. Returns false if the context is cancelled meanwhile
+		send := func(evs []Event, wait bool) bool {
+			for _, ev := range evs {
+				queue.push(ev)
+			}
+			for queue.full() || (wait && !queue.empty()) {
+				select {
+				case events <- queue.first():
+					queue.pop()
+				case <-ctx.Done():
+					return false
 				}
-				game.removeLines()
-				game.renewColumn()
+			}
+			return true
+		}
 
-				if game.isOver() {
-					return
+		// cancel ends the game, delivering the final event only if it is read within a grace period,
+		// as events may not be read anymore. Events still waiting to be read are discarded
+		cancel := func() {
+			timer := time.NewTimer(cancelGracePeriod)
+			defer timer.Stop()
+			queue.discard()
+			for _, ev := range engine.Cancel(ctx.Err()) {
+				if over, ok := ev.(EventGameOver); ok {
+					over.Dropped = queue.dropped
+					ev = over
+				}
+				select {
+				case events <- ev:
+				case <-timer.C:
 				}
 			}
 		}
+
+		if !send(engine.Start(), engine.IsOver()) {
+			cancel()
+			return
+		}
+		if engine.IsOver() {
+			return
+		}
+		for {
+			// Queued events are delivered while waiting for commands or ticks, so a slow reader
+			// does not stop the game unless the drop policy says so
+			var out chan Event
+			var first Event
+			if !queue.empty() {
+				out = events
+				first = queue.first()
+			}
+
+			var evs []Event
+			select {
+			case out <- first:
+				queue.pop()
+				continue
+			case comm, open := <-commands:
+				if !open {
+					// Receiving from a nil channel blocks forever, so no more commands are processed
+					commands = nil
+					continue
+				}
+				evs = engine.Step(comm)
+			case <-ticker.C():
+				evs = engine.Tick()
+			case <-ctx.Done():
+				cancel()
+				return
+			}
+			if !send(evs, engine.IsOver()) {
+				cancel()
+				return
+			}
+			if engine.IsOver() {
+				return
+			}
+			if engine.Speed() != speed {
+				// The new ticker is created before stopping the old one, so manual clocks always have
+				// a running ticker while the game goes on
+				old := ticker
+				speed = engine.Speed()
+				ticker = clock.NewTicker(interval(speed))
+				old.Stop()
+			}
+		}
 	}()
 
-	return game.events, nil
+	return events
 }
 
-func newGame(p Well, build TilesetBuilder, cfg Config) (*game, error) {
-	if err := validateConfig(cfg); err != nil {
-		return nil, err
+// interval returns the time between two ticks at the passed speed in cells/second
+func interval(speed float64) time.Duration {
+	return time.Duration(nanosecond / speed)
+}
+
+// clock returns the clock set in the configuration, or the wall clock if none was set
+func (c Config) clock() Clock {
+	if c.Clock == nil {
+		return wallClock{}
 	}
+	return c.Clock
+}
 
-	return &game{
-		well:        p.copy(),
-		column:      &Column{Tileset: [3]int{}},
-		nextTileset: build(maxTile),
-		level:       1,
-		cfg:         cfg,
-		events:      make(chan interface{}),
-		speed:       cfg.InitialSpeed,
-		ticker:      time.NewTicker(time.Duration(nanosecond / cfg.InitialSpeed)),
-		build:       build,
-	}, nil
+// scorer returns the scorer set in the configuration, or DefaultScorer if none was set
+func (c Config) scorer() Scorer {
+	if c.Scorer == nil {
+		return DefaultScorer
+	}
+	return c.Scorer
+}
+
+// numColors returns the number of colors set in the configuration, or DefaultNumColors if none was set
+func (c Config) numColors() int {
+	if c.NumColors == 0 {
+		return DefaultNumColors
+	}
+	return c.NumColors
+}
+
+// columnLength returns the column length set in the configuration, or DefaultColumnLength if none was set
+func (c Config) columnLength() int {
+	if c.ColumnLength == 0 {
+		return DefaultColumnLength
+	}
+	return c.ColumnLength
+}
+
+// startLevel returns the start level set in the configuration, or 1 if none was set
+func (c Config) startLevel() int {
+	if c.StartLevel == 0 {
+		return 1
+	}
+	return c.StartLevel
+}
+
+// minMatch returns the minimum match length set in the configuration, or DefaultMinMatch if none was set
+func (c Config) minMatch() int {
+	if c.MinMatch == 0 {
+		return DefaultMinMatch
+	}
+	return c.MinMatch
 }
 
 func validateConfig(cfg Config) error {
@@ -144,77 +352,35 @@ func validateConfig(cfg Config) error {
 	if cfg.MaxSpeed <= 0 {
 		return fmt.Errorf(errorLessEqualZeroMaxSpeed)
 	}
-	return nil
-}
-
-func (g *game) execute(comm int) {
-	if comm == CommandWaitSwitch {
-		g.wait = !g.wait
-		return
-	}
-	if comm == CommandPauseSwitch {
-		g.paused = !g.paused
-		return
-	}
-	if !g.paused && !g.wait {
-		switch comm {
-		case CommandLeft:
-			g.column.left(g.well)
-		case CommandRight:
-			g.column.right(g.well)
-		case CommandDown:
-			g.column.down(g.well)
-		case CommandRotate:
-			g.column.rotate()
-		}
+	if cfg.NumColors < 0 || cfg.NumColors > MaxNumColors {
+		return fmt.Errorf(errorNumColorsOutOfRange)
 	}
-	g.events <- EventUpdated{
-		Column: *g.column,
+	if cfg.ColumnLength < 0 {
+		return fmt.Errorf(errorNegativeColumnLength)
 	}
-}
-
-func (g *game) removeLines() {
-	g.well.lock(g.column)
-	removed := g.well.markTilesToRemove()
-	combo := 1
-	for removed > 0 {
-		g.totalRemoved += removed
-		if g.totalRemoved/g.cfg.NumberTilesForNextLevel > g.level-1 {
-			g.level++
-			g.speedUp()
-		}
-		g.events <- EventScored{
-			Well:    g.well.copy(),
-			Combo:   combo,
-			Level:   g.level,
-			Removed: removed,
-		}
-		combo++
-		g.well.settle()
-		removed = g.well.markTilesToRemove()
+	if cfg.MinMatch < 0 || cfg.MinMatch == 1 {
+		return fmt.Errorf(errorMinMatchOutOfRange)
 	}
-}
-
-func (g *game) speedUp() {
-	speed := g.speed + g.cfg.SpeedIncrement
-	if speed < g.cfg.MaxSpeed {
-		g.ticker.Stop()
-		g.speed = speed
-		g.ticker = time.NewTicker(time.Duration(nanosecond / speed))
+	if cfg.EventBuffer < 0 {
+		return fmt.Errorf(errorNegativeEventBuffer)
 	}
-}
-
-func (g *game) isOver() bool {
-	return g.well[g.well.width()/2][0] != Empty
-}
-
-func (g *game) renewColumn() {
-	g.column.reset(g.nextTileset, g.well.width()/2)
-	g.nextTileset = g.build(maxTile)
-
-	g.events <- EventRenewed{
-		Well:        g.well.copy(),
-		Column:      *g.column,
-		NextTileset: g.nextTileset,
+	if cfg.LockDelay < 0 {
+		return fmt.Errorf(errorNegativeLockDelay)
 	}
+	if cfg.LockResets < 0 {
+		return fmt.Errorf(errorNegativeLockResets)
+	}
+	if cfg.ClearDelay < 0 {
+		return fmt.Errorf(errorNegativeClearDelay)
+	}
+	if cfg.SpawnDelay < 0 {
+		return fmt.Errorf(errorNegativeSpawnDelay)
+	}
+	if cfg.StartLevel < 0 {
+		return fmt.Errorf(errorNegativeStartLevel)
+	}
+	if cfg.DropPolicy < PolicyBlock || cfg.DropPolicy > PolicyCoalesceUpdates {
+		return fmt.Errorf(errorUnknownDropPolicy)
+	}
+	return nil
 }
@@ -1,6 +1,8 @@
 package doric_test
 
 import (
+	"context"
+	"errors"
 	"reflect"
 	"testing"
 	"time"
@@ -10,13 +12,13 @@ import (
 
 // mockTilesetBuilder implements the TilesFactory function to generate random tilesets
 type mockTilesetBuilder struct {
-	Tilesets [][3]int
+	Tilesets [][]int
 	current  int
 }
 
 // build returns a tileset in the Tilesets property in the same order
 // If all tilesets inside Tilesets were returned, the slice is ran again from the beginning
-func (m *mockTilesetBuilder) build(n int) [3]int {
+func (m *mockTilesetBuilder) build(n, length int) []int {
 	if m.current == len(m.Tilesets) {
 		m.current = 0
 	}
@@ -34,12 +36,12 @@ func defaultConfig() doric.Config {
 	}
 }
 
-func setup(t *testing.T, cfg doric.Config, well doric.Well, ts [][3]int) (chan<- int, <-chan interface{}, <-chan time.Time) {
+func setup(t *testing.T, cfg doric.Config, well doric.Well, ts [][]int) (chan<- doric.Command, <-chan doric.Event, <-chan time.Time) {
 	timeout := time.After(1 * time.Second)
 	factory := &mockTilesetBuilder{
 		Tilesets: ts,
 	}
-	commands := make(chan int)
+	commands := make(chan doric.Command)
 	events, err := doric.Play(well, factory.build, cfg, commands)
 	if err != nil {
 		t.Fatalf(err.Error())
@@ -93,14 +95,124 @@ func TestConfigValidations(t *testing.T) {
 				MaxSpeed:                0,
 			},
 		},
+		{
+			name: "Must return error if NumColors < 0",
+			cfg: doric.Config{
+				NumberTilesForNextLevel: 10,
+				InitialSpeed:            1,
+				SpeedIncrement:          1,
+				MaxSpeed:                10,
+				NumColors:               -1,
+			},
+		},
+		{
+			name: "Must return error if NumColors > MaxNumColors",
+			cfg: doric.Config{
+				NumberTilesForNextLevel: 10,
+				InitialSpeed:            1,
+				SpeedIncrement:          1,
+				MaxSpeed:                10,
+				NumColors:               doric.MaxNumColors + 1,
+			},
+		},
+		{
+			name: "Must return error if ColumnLength < 0",
+			cfg: doric.Config{
+				NumberTilesForNextLevel: 10,
+				InitialSpeed:            1,
+				SpeedIncrement:          1,
+				MaxSpeed:                10,
+				ColumnLength:            -1,
+			},
+		},
+		{
+			name: "Must return error if MinMatch == 1",
+			cfg: doric.Config{
+				NumberTilesForNextLevel: 10,
+				InitialSpeed:            1,
+				SpeedIncrement:          1,
+				MaxSpeed:                10,
+				MinMatch:                1,
+			},
+		},
+		{
+			name: "Must return error if EventBuffer < 0",
+			cfg: doric.Config{
+				NumberTilesForNextLevel: 10,
+				InitialSpeed:            1,
+				SpeedIncrement:          1,
+				MaxSpeed:                10,
+				EventBuffer:             -1,
+			},
+		},
+		{
+			name: "Must return error if DropPolicy is unknown",
+			cfg: doric.Config{
+				NumberTilesForNextLevel: 10,
+				InitialSpeed:            1,
+				SpeedIncrement:          1,
+				MaxSpeed:                10,
+				DropPolicy:              doric.PolicyCoalesceUpdates + 1,
+			},
+		},
+		{
+			name: "Must return error if LockDelay < 0",
+			cfg: doric.Config{
+				NumberTilesForNextLevel: 10,
+				InitialSpeed:            1,
+				SpeedIncrement:          1,
+				MaxSpeed:                10,
+				LockDelay:               -1,
+			},
+		},
+		{
+			name: "Must return error if LockResets < 0",
+			cfg: doric.Config{
+				NumberTilesForNextLevel: 10,
+				InitialSpeed:            1,
+				SpeedIncrement:          1,
+				MaxSpeed:                10,
+				LockResets:              -1,
+			},
+		},
+		{
+			name: "Must return error if ClearDelay < 0",
+			cfg: doric.Config{
+				NumberTilesForNextLevel: 10,
+				InitialSpeed:            1,
+				SpeedIncrement:          1,
+				MaxSpeed:                10,
+				ClearDelay:              -1,
+			},
+		},
+		{
+			name: "Must return error if SpawnDelay < 0",
+			cfg: doric.Config{
+				NumberTilesForNextLevel: 10,
+				InitialSpeed:            1,
+				SpeedIncrement:          1,
+				MaxSpeed:                10,
+				SpawnDelay:              -1,
+			},
+		},
+		{
+			name: "Must return error if StartLevel < 0",
+			cfg: doric.Config{
+				NumberTilesForNextLevel: 10,
+				InitialSpeed:            1,
+				SpeedIncrement:          1,
+				MaxSpeed:                10,
+				StartLevel:              -1,
+			},
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			well := doric.NewWell(doric.StandardWidth, doric.StandardHeight)
-			commands := make(chan int)
+			commands := make(chan doric.Command)
 			factory := &mockTilesetBuilder{
-				Tilesets: [][3]int{{1, 2, 3}},
+				Tilesets: [][]int{{1, 2, 3}},
 			}
 
 			_, err := doric.Play(well, factory.build, test.cfg, commands)
@@ -111,6 +223,20 @@ func TestConfigValidations(t *testing.T) {
 	}
 }
 
+func TestPlayInvalidWell(t *testing.T) {
+	well := parseWell(t, `
+		..1...
+		......
+		..2...
+	`)
+	factory := &mockTilesetBuilder{
+		Tilesets: [][]int{{1, 2, 3}},
+	}
+	if _, err := doric.Play(well, factory.build, defaultConfig(), make(chan doric.Command)); !errors.Is(err, doric.ErrFloatingTile) {
+		t.Errorf("Expected error %v but got %v", doric.ErrFloatingTile, err)
+	}
+}
+
 func TestGameOver(t *testing.T) {
 	well := doric.NewWell(doric.StandardWidth, 1)
 	well[3][0] = 1
@@ -118,7 +244,7 @@ func TestGameOver(t *testing.T) {
 		t,
 		defaultConfig(),
 		well,
-		[][3]int{{1, 1, 1}},
+		[][]int{{1, 1, 1}},
 	)
 
 	for {
@@ -138,7 +264,7 @@ func TestQuit(t *testing.T) {
 		t,
 		defaultConfig(),
 		doric.NewWell(doric.StandardWidth, doric.StandardHeight),
-		[][3]int{{1, 2, 3}},
+		[][]int{{1, 2, 3}},
 	)
 	commands <- doric.CommandQuit
 
@@ -154,55 +280,293 @@ func TestQuit(t *testing.T) {
 	}
 }
 
+func TestPlayContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	factory := &mockTilesetBuilder{
+		Tilesets: [][]int{{1, 2, 3}},
+	}
+	events, err := doric.PlayContext(ctx, doric.NewWell(doric.StandardWidth, doric.StandardHeight), factory.build, defaultConfig(), make(chan doric.Command))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	<-events
+	cancel()
+
+	var last doric.Event
+	for ev := range events {
+		last = ev
+	}
+	over, ok := last.(doric.EventGameOver)
+	if !ok {
+		t.Fatalf("Expected last event to be an EventGameOver, got %T", last)
+	}
+	if over.Reason != doric.ReasonCancelled || over.Err != context.Canceled {
+		t.Errorf("Expected game to be cancelled, got reason %s and error %v", over.Reason, over.Err)
+	}
+}
+
+func TestPlayContextNotReading(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	factory := &mockTilesetBuilder{
+		Tilesets: [][]int{{1, 2, 3}},
+	}
+	events, err := doric.PlayContext(ctx, doric.NewWell(doric.StandardWidth, doric.StandardHeight), factory.build, defaultConfig(), make(chan doric.Command))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	cancel()
+
+	// Give the game time to give up delivering its final event
+	time.Sleep(200 * time.Millisecond)
+	select {
+	case _, open := <-events:
+		if open {
+			t.Errorf("Expected events channel to be closed")
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Game goroutine should have finished")
+	}
+}
+
+func TestPlayContextDropped(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	clock := doric.NewManualClock(time.Now())
+	cfg := defaultConfig()
+	cfg.Clock = clock
+	cfg.DropPolicy = doric.PolicyCoalesceUpdates
+	factory := &mockTilesetBuilder{
+		Tilesets: [][]int{{1, 2, 3}},
+	}
+	events, err := doric.PlayContext(ctx, doric.NewWell(doric.StandardWidth, doric.StandardHeight), factory.build, cfg, make(chan doric.Command))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// Events are not read while the game goes on, so updates are coalesced
+	for i := 0; i < 5; i++ {
+		clock.Tick()
+	}
+	cancel()
+
+	var received []doric.Event
+	for ev := range events {
+		received = append(received, ev)
+	}
+	over, ok := received[len(received)-1].(doric.EventGameOver)
+	if !ok {
+		t.Fatalf("Expected last event to be an EventGameOver, got %v", received[len(received)-1])
+	}
+	if expected := over.Seq - uint64(len(received)); over.Dropped != expected || expected == 0 {
+		t.Errorf("Expected %d dropped events but got %d", expected, over.Dropped)
+	}
+}
+
+func TestDropPolicies(t *testing.T) {
+	tests := []struct {
+		name            string
+		policy          doric.DropPolicy
+		buffer          int
+		expectedRows    []int
+		expectedDropped uint64
+	}{
+		{
+			name:            "Must keep only the newest update when coalescing",
+			policy:          doric.PolicyCoalesceUpdates,
+			buffer:          0,
+			expectedRows:    []int{5},
+			expectedDropped: 4,
+		},
+		{
+			name:            "Must drop oldest updates when buffer is full",
+			policy:          doric.PolicyDropOldestUpdate,
+			buffer:          4,
+			expectedRows:    []int{3, 4, 5},
+			expectedDropped: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clock := doric.NewManualClock(time.Now())
+			cfg := defaultConfig()
+			cfg.Clock = clock
+			cfg.DropPolicy = test.policy
+			cfg.EventBuffer = test.buffer
+			commands := make(chan doric.Command)
+			factory := &mockTilesetBuilder{
+				Tilesets: [][]int{{1, 2, 3}},
+			}
+			events, err := doric.Play(doric.NewWell(doric.StandardWidth, doric.StandardHeight), factory.build, cfg, commands)
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+
+			// Events are not read while the game goes on, so updates pile up
+			for i := 0; i < 5; i++ {
+				clock.Tick()
+			}
+			commands <- doric.CommandQuit
+
+			var rows []int
+			var last doric.Event
+			for ev := range events {
+				if upd, ok := ev.(doric.EventUpdated); ok {
+					rows = append(rows, upd.Column.Y)
+				}
+				last = ev
+			}
+			if !reflect.DeepEqual(rows, test.expectedRows) {
+				t.Errorf("Expected updates with rows %v but got %v", test.expectedRows, rows)
+			}
+			if over := last.(doric.EventGameOver); over.Dropped != test.expectedDropped {
+				t.Errorf("Expected %d dropped events but got %d", test.expectedDropped, over.Dropped)
+			}
+		})
+	}
+}
+
+func TestDropOldestUpdateWithoutBuffer(t *testing.T) {
+	clock := doric.NewManualClock(time.Now())
+	cfg := defaultConfig()
+	cfg.Clock = clock
+	cfg.DropPolicy = doric.PolicyDropOldestUpdate
+	commands := make(chan doric.Command)
+	factory := &mockTilesetBuilder{
+		Tilesets: [][]int{{1, 2, 3}},
+	}
+	events, err := doric.Play(doric.NewWell(doric.StandardWidth, doric.StandardHeight), factory.build, cfg, commands)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	<-events
+
+	// Events are read as soon as they are produced, so no update must be lost
+	var rows []int
+	for i := 0; i < 3; i++ {
+		clock.Tick()
+		if upd, ok := (<-events).(doric.EventUpdated); ok {
+			rows = append(rows, upd.Column.Y)
+		}
+	}
+	commands <- doric.CommandLeft
+	if upd, ok := (<-events).(doric.EventUpdated); ok {
+		rows = append(rows, upd.Column.Y)
+	}
+	commands <- doric.CommandQuit
+
+	var last doric.Event
+	for ev := range events {
+		last = ev
+	}
+	if expected := []int{1, 2, 3, 3}; !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Expected updates with rows %v but got %v", expected, rows)
+	}
+	if over := last.(doric.EventGameOver); over.Dropped != 0 {
+		t.Errorf("Expected no dropped events but got %d", over.Dropped)
+	}
+}
+
+func TestPlayUntyped(t *testing.T) {
+	commands := make(chan int)
+	factory := &mockTilesetBuilder{
+		Tilesets: [][]int{{1, 2, 3}},
+	}
+	events, err := doric.PlayUntyped(doric.NewWell(doric.StandardWidth, doric.StandardHeight), factory.build, defaultConfig(), commands)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if ev := <-events; reflect.TypeOf(ev) != reflect.TypeOf(doric.EventRenewed{}) {
+		t.Errorf("Expected an EventRenewed but got %T", ev)
+	}
+	commands <- int(doric.CommandQuit)
+	for range events {
+	}
+}
+
+func TestManualClock(t *testing.T) {
+	clock := doric.NewManualClock(time.Now())
+	cfg := defaultConfig()
+	cfg.Clock = clock
+	commands, events, timeout := setup(
+		t,
+		cfg,
+		doric.NewWell(doric.StandardWidth, doric.StandardHeight),
+		[][]int{{1, 2, 3}},
+	)
+
+	for i := 1; i <= 3; i++ {
+		clock.Tick()
+		select {
+		case ev := <-events:
+			if upd, ok := ev.(doric.EventUpdated); !ok || upd.Column.Y != i {
+				t.Errorf("Expected column to be at row %d, got %v", i, ev)
+			}
+		case <-timeout:
+			t.Fatalf("Test timed out")
+		}
+	}
+
+	commands <- doric.CommandQuit
+	for range events {
+	}
+	ticked := make(chan struct{})
+	go func() {
+		clock.Tick()
+		close(ticked)
+	}()
+	select {
+	case <-ticked:
+	case <-timeout:
+		t.Fatalf("Expected Tick not to block after the game is over")
+	}
+}
+
+func TestScaledClock(t *testing.T) {
+	for _, factor := range []float64{0, -1} {
+		if _, err := doric.ScaledClock(factor); err == nil {
+			t.Errorf("Expected error creating a ScaledClock with factor %v", factor)
+		}
+	}
+
+	clock, err := doric.ScaledClock(100)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	start := clock.Now()
+	time.Sleep(10 * time.Millisecond)
+	if elapsed := clock.Now().Sub(start); elapsed < time.Second {
+		t.Errorf("Expected at least %v to pass in the scaled clock but got %v", time.Second, elapsed)
+	}
+}
+
 func TestPause(t *testing.T) {
 	tests := []struct {
-		name           string
-		command        int
-		expectedUpdate doric.EventUpdated
+		name    string
+		command doric.Command
 	}{
 		{
 			name:    "Must not move left if paused",
 			command: doric.CommandLeft,
-			expectedUpdate: doric.EventUpdated{
-				Column: doric.Column{
-					Tileset: [3]int{1, 2, 3},
-					X:       3,
-					Y:       0,
-				},
-			},
 		},
 		{
 			name:    "Must not move right if paused",
 			command: doric.CommandRight,
-			expectedUpdate: doric.EventUpdated{
-				Column: doric.Column{
-					Tileset: [3]int{1, 2, 3},
-					X:       3,
-					Y:       0,
-				},
-			},
 		},
 		{
 			name:    "Must not move down if paused",
 			command: doric.CommandDown,
-			expectedUpdate: doric.EventUpdated{
-				Column: doric.Column{
-					Tileset: [3]int{1, 2, 3},
-					X:       3,
-					Y:       0,
-				},
-			},
+		},
+		{
+			name:    "Must not drop if paused",
+			command: doric.CommandDrop,
 		},
 		{
 			name:    "Must not rotate if paused",
 			command: doric.CommandRotate,
-			expectedUpdate: doric.EventUpdated{
-				Column: doric.Column{
-					Tileset: [3]int{1, 2, 3},
-					X:       3,
-					Y:       0,
-				},
-			},
+		},
+		{
+			name:    "Must not rotate reverse if paused",
+			command: doric.CommandRotateReverse,
 		},
 	}
 
@@ -212,7 +576,7 @@ func TestPause(t *testing.T) {
 				t,
 				defaultConfig(),
 				doric.NewWell(doric.StandardWidth, doric.StandardHeight),
-				[][3]int{{1, 2, 3}},
+				[][]int{{1, 2, 3}},
 			)
 
 			commands <- doric.CommandPauseSwitch
@@ -220,7 +584,7 @@ func TestPause(t *testing.T) {
 
 			select {
 			case ev := <-events:
-				if upd, ok := ev.(doric.EventUpdated); ok && reflect.DeepEqual(upd, test.expectedUpdate) {
+				if rej, ok := ev.(doric.EventRejected); ok && rej.Command == test.command && rej.Reason == doric.RejectPaused {
 					break
 				}
 				t.Errorf("Current column must not move or rotate if game is paused")
@@ -234,53 +598,32 @@ func TestPause(t *testing.T) {
 
 func TestWait(t *testing.T) {
 	tests := []struct {
-		name           string
-		command        int
-		expectedUpdate doric.EventUpdated
+		name    string
+		command doric.Command
 	}{
 		{
 			name:    "Must not move left if waiting",
 			command: doric.CommandLeft,
-			expectedUpdate: doric.EventUpdated{
-				Column: doric.Column{
-					Tileset: [3]int{1, 2, 3},
-					X:       3,
-					Y:       0,
-				},
-			},
 		},
 		{
 			name:    "Must not move right if waiting",
 			command: doric.CommandRight,
-			expectedUpdate: doric.EventUpdated{
-				Column: doric.Column{
-					Tileset: [3]int{1, 2, 3},
-					X:       3,
-					Y:       0,
-				},
-			},
 		},
 		{
 			name:    "Must not move down if waiting",
 			command: doric.CommandDown,
-			expectedUpdate: doric.EventUpdated{
-				Column: doric.Column{
-					Tileset: [3]int{1, 2, 3},
-					X:       3,
-					Y:       0,
-				},
-			},
+		},
+		{
+			name:    "Must not drop if waiting",
+			command: doric.CommandDrop,
 		},
 		{
 			name:    "Must not rotate if waiting",
 			command: doric.CommandRotate,
-			expectedUpdate: doric.EventUpdated{
-				Column: doric.Column{
-					Tileset: [3]int{1, 2, 3},
-					X:       3,
-					Y:       0,
-				},
-			},
+		},
+		{
+			name:    "Must not rotate reverse if waiting",
+			command: doric.CommandRotateReverse,
 		},
 	}
 
@@ -290,7 +633,7 @@ func TestWait(t *testing.T) {
 				t,
 				defaultConfig(),
 				doric.NewWell(doric.StandardWidth, doric.StandardHeight),
-				[][3]int{{1, 2, 3}},
+				[][]int{{1, 2, 3}},
 			)
 
 			commands <- doric.CommandWaitSwitch
@@ -298,7 +641,7 @@ func TestWait(t *testing.T) {
 
 			select {
 			case ev := <-events:
-				if upd, ok := ev.(doric.EventUpdated); ok && reflect.DeepEqual(upd, test.expectedUpdate) {
+				if rej, ok := ev.(doric.EventRejected); ok && rej.Command == test.command && rej.Reason == doric.RejectPaused {
 					break
 				}
 				t.Errorf("Current column must not move or rotate if game is waiting")
@@ -313,7 +656,7 @@ func TestWait(t *testing.T) {
 func TestCommands(t *testing.T) {
 	tests := []struct {
 		name           string
-		command        int
+		command        doric.Command
 		expectedUpdate doric.EventUpdated
 	}{
 		{
@@ -321,10 +664,11 @@ func TestCommands(t *testing.T) {
 			command: doric.CommandLeft,
 			expectedUpdate: doric.EventUpdated{
 				Column: doric.Column{
-					Tileset: [3]int{1, 2, 3},
+					Tileset: []int{1, 2, 3},
 					X:       2,
 					Y:       0,
 				},
+				LandingY: 12,
 			},
 		},
 		{
@@ -332,10 +676,11 @@ func TestCommands(t *testing.T) {
 			command: doric.CommandRight,
 			expectedUpdate: doric.EventUpdated{
 				Column: doric.Column{
-					Tileset: [3]int{1, 2, 3},
+					Tileset: []int{1, 2, 3},
 					X:       4,
 					Y:       0,
 				},
+				LandingY: 12,
 			},
 		},
 		{
@@ -343,10 +688,11 @@ func TestCommands(t *testing.T) {
 			command: doric.CommandDown,
 			expectedUpdate: doric.EventUpdated{
 				Column: doric.Column{
-					Tileset: [3]int{1, 2, 3},
+					Tileset: []int{1, 2, 3},
 					X:       3,
 					Y:       1,
 				},
+				LandingY: 12,
 			},
 		},
 		{
@@ -354,10 +700,23 @@ func TestCommands(t *testing.T) {
 			command: doric.CommandRotate,
 			expectedUpdate: doric.EventUpdated{
 				Column: doric.Column{
-					Tileset: [3]int{3, 1, 2},
+					Tileset: []int{3, 1, 2},
+					X:       3,
+					Y:       0,
+				},
+				LandingY: 12,
+			},
+		},
+		{
+			name:    "Must rotate reverse",
+			command: doric.CommandRotateReverse,
+			expectedUpdate: doric.EventUpdated{
+				Column: doric.Column{
+					Tileset: []int{2, 3, 1},
 					X:       3,
 					Y:       0,
 				},
+				LandingY: 12,
 			},
 		},
 	}
@@ -368,13 +727,13 @@ func TestCommands(t *testing.T) {
 				t,
 				defaultConfig(),
 				doric.NewWell(doric.StandardWidth, doric.StandardHeight),
-				[][3]int{{1, 2, 3}},
+				[][]int{{1, 2, 3}},
 			)
 			commands <- test.command
 
 			select {
 			case ev := <-events:
-				if upd, ok := ev.(doric.EventUpdated); ok && reflect.DeepEqual(upd, test.expectedUpdate) {
+				if upd, ok := ev.(doric.EventUpdated); ok && reflect.DeepEqual(upd.Column, test.expectedUpdate.Column) && upd.LandingY == test.expectedUpdate.LandingY {
 					break
 				}
 				t.Errorf("Current column must move or rotate")
@@ -389,40 +748,43 @@ func TestCommands(t *testing.T) {
 func TestWellBounds(t *testing.T) {
 	tests := []struct {
 		name           string
-		command        int
+		commands       []doric.Command
 		expectedUpdate doric.EventUpdated
 	}{
 		{
-			name:    "Must clash with left bound when moving left",
-			command: doric.CommandLeft,
+			name:     "Must clash with left bound when moving left",
+			commands: []doric.Command{doric.CommandLeft, doric.CommandLeft},
 			expectedUpdate: doric.EventUpdated{
 				Column: doric.Column{
-					Tileset: [3]int{1, 2, 3},
+					Tileset: []int{1, 2, 3},
 					X:       0,
 					Y:       0,
 				},
+				LandingY: 0,
 			},
 		},
 		{
-			name:    "Must clash with right bound when moving right",
-			command: doric.CommandRight,
+			name:     "Must clash with right bound when moving right",
+			commands: []doric.Command{doric.CommandRight, doric.CommandRight},
 			expectedUpdate: doric.EventUpdated{
 				Column: doric.Column{
-					Tileset: [3]int{1, 2, 3},
-					X:       0,
+					Tileset: []int{1, 2, 3},
+					X:       2,
 					Y:       0,
 				},
+				LandingY: 0,
 			},
 		},
 		{
-			name:    "Must clash with bottom bound when moving down",
-			command: doric.CommandDown,
+			name:     "Must clash with bottom bound when moving down",
+			commands: []doric.Command{doric.CommandDown},
 			expectedUpdate: doric.EventUpdated{
 				Column: doric.Column{
-					Tileset: [3]int{1, 2, 3},
-					X:       0,
+					Tileset: []int{1, 2, 3},
+					X:       1,
 					Y:       0,
 				},
+				LandingY: 0,
 			},
 		},
 	}
@@ -432,19 +794,21 @@ func TestWellBounds(t *testing.T) {
 			commands, events, timeout := setup(
 				t,
 				defaultConfig(),
-				doric.NewWell(1, 1),
-				[][3]int{{1, 2, 3}},
+				doric.NewWell(3, 1),
+				[][]int{{1, 2, 3}},
 			)
-			commands <- test.command
 
-			select {
-			case ev := <-events:
-				if upd, ok := ev.(doric.EventUpdated); ok && reflect.DeepEqual(upd, test.expectedUpdate) {
-					break
+			var ev doric.Event
+			for _, comm := range test.commands {
+				commands <- comm
+				select {
+				case ev = <-events:
+				case <-timeout:
+					t.Fatalf("Test timed out")
 				}
+			}
+			if upd, ok := ev.(doric.EventUpdated); !ok || !reflect.DeepEqual(upd.Column, test.expectedUpdate.Column) || upd.LandingY != test.expectedUpdate.LandingY {
 				t.Errorf("Current column must not move as it would clash with well's borders")
-			case <-timeout:
-				t.Errorf("Test timed out")
 			}
 		})
 	}
@@ -455,90 +819,90 @@ func TestScored(t *testing.T) {
 		name                    string
 		numberTilesForNextLevel int
 		well                    doric.Well
-		tilesets                [][3]int
+		tilesets                [][]int
 		expectedWell            doric.Well
 		expectedRenewedWell     doric.Well
 		expectedRemoved         int
 		expectedLevel           int
-		expectedCurrent         [3]int
+		expectedCurrent         []int
 	}{
 		{
 			name:                    "Scored with no level up",
 			numberTilesForNextLevel: 20,
-			tilesets: [][3]int{
+			tilesets: [][]int{
 				{1, 1, 1},
 				{4, 5, 6},
 			},
-			well: transpose(doric.Well{
-				[]int{0, 1, 0, 0, 0, 0},
-				[]int{1, 1, 0, 0, 1, 1},
-				[]int{1, 1, 1, 0, 1, 1},
-			}),
-			expectedWell: transpose(doric.Well{
-				[]int{0, -1, 0, -1, 0, 0},
-				[]int{1, -1, 0, -1, -1, -1},
-				[]int{-1, -1, -1, -1, -1, -1},
-			}),
-			expectedRenewedWell: transpose(doric.Well{
-				[]int{0, 0, 0, 0, 0, 0},
-				[]int{0, 0, 0, 0, 0, 0},
-				[]int{1, 0, 0, 0, 0, 0},
-			}),
+			well: parseWell(t, `
+				.1....
+				11..11
+				111.11
+			`),
+			expectedWell: parseWell(t, `
+				.x.x..
+				1x.xxx
+				xxxxxx
+			`),
+			expectedRenewedWell: parseWell(t, `
+				......
+				......
+				1.....
+			`),
 			expectedRemoved: 12,
 			expectedLevel:   1,
-			expectedCurrent: [3]int{4, 5, 6},
+			expectedCurrent: []int{4, 5, 6},
 		},
 		{
 			name:                    "Scored with level up",
 			numberTilesForNextLevel: 1,
-			tilesets: [][3]int{
+			tilesets: [][]int{
 				{1, 1, 1},
 				{4, 5, 6},
 			},
-			well: transpose(doric.Well{
-				[]int{0, 1, 0, 0, 0, 0},
-				[]int{1, 1, 0, 0, 1, 1},
-				[]int{1, 1, 1, 0, 1, 1},
-			}),
-			expectedWell: transpose(doric.Well{
-				[]int{0, -1, 0, -1, 0, 0},
-				[]int{1, -1, 0, -1, -1, -1},
-				[]int{-1, -1, -1, -1, -1, -1},
-			}),
-			expectedRenewedWell: transpose(doric.Well{
-				[]int{0, 0, 0, 0, 0, 0},
-				[]int{0, 0, 0, 0, 0, 0},
-				[]int{1, 0, 0, 0, 0, 0},
-			}),
+			well: parseWell(t, `
+				.1....
+				11..11
+				111.11
+			`),
+			expectedWell: parseWell(t, `
+				.x.x..
+				1x.xxx
+				xxxxxx
+			`),
+			expectedRenewedWell: parseWell(t, `
+				......
+				......
+				1.....
+			`),
 			expectedRemoved: 12,
 			expectedLevel:   2,
-			expectedCurrent: [3]int{4, 5, 6},
+			expectedCurrent: []int{4, 5, 6},
 		},
 		{
 			name:                    "Diagonal lines",
 			numberTilesForNextLevel: 20,
-			tilesets: [][3]int{
+			tilesets: [][]int{
 				{1, 1, 1},
 				{4, 5, 6},
 			},
-			well: transpose(doric.Well{
-				[]int{1, 0, 0, 0, 0, 1},
-				[]int{2, 1, 0, 0, 1, 2},
-				[]int{3, 2, 1, 0, 2, 3},
-			}),
-			expectedWell: transpose(doric.Well{
-				[]int{-1, 0, 0, -1, 0, -1},
-				[]int{2, -1, 0, -1, -1, 2},
-				[]int{3, 2, -1, -1, 2, 3},
-			}),
-			expectedRenewedWell: transpose(doric.Well{
-				[]int{0, 0, 0, 0, 0, 0},
-				[]int{2, 0, 0, 0, 0, 2},
-				[]int{3, 2, 0, 0, 2, 3},
-			}),
+			well: parseWell(t, `
+				1....1
+				21..12
+				321.23
+			`),
+			expectedWell: parseWell(t, `
+				x..x.x
+				2x.xx2
+				32xx23
+			`),
+			expectedRenewedWell: parseWell(t, `
+				......
+				2....2
+				32..23
+			`),
 			expectedRemoved: 8,
 			expectedLevel:   1,
-			expectedCurrent: [3]int{4, 5, 6},
+			expectedCurrent: []int{4, 5, 6},
 		},
 	}
 
@@ -572,7 +936,7 @@ func TestScored(t *testing.T) {
 							t.Errorf("Expected well %v but got %v", test.expectedWell, asserted.Well)
 						}
 					case doric.EventRenewed:
-						if asserted.Column.Tileset != test.expectedCurrent {
+						if !reflect.DeepEqual(asserted.Column.Tileset, test.expectedCurrent) {
 							t.Errorf(
 								"Expected that the next column was copied to the current one with values %v, got %v",
 								test.expectedCurrent,
@@ -598,32 +962,32 @@ func TestScoredCombo(t *testing.T) {
 		name                    string
 		numberTilesForNextLevel int
 		well                    doric.Well
-		tilesets                [][3]int
+		tilesets                [][]int
 		expectedWells           []doric.Well
 	}{
 		{
 			name:                    "Scored with combo",
 			numberTilesForNextLevel: 20,
-			tilesets: [][3]int{
+			tilesets: [][]int{
 				{1, 2, 3},
 				{4, 5, 6},
 			},
-			well: transpose(doric.Well{
-				[]int{0, 0, 0, 0, 0, 0},
-				[]int{0, 0, 0, 0, 0, 0},
-				[]int{0, 2, 2, 0, 1, 1},
-			}),
+			well: parseWell(t, `
+				......
+				......
+				.22.11
+			`),
 			expectedWells: []doric.Well{
-				transpose(doric.Well{
-					[]int{0, 0, 0, 3, 0, 0},
-					[]int{0, 0, 0, 2, 0, 0},
-					[]int{0, 2, 2, -1, -1, -1},
-				}),
-				transpose(doric.Well{
-					[]int{0, 0, 0, 0, 0, 0},
-					[]int{0, 0, 0, 3, 0, 0},
-					[]int{0, -1, -1, -1, 0, 0},
-				}),
+				parseWell(t, `
+					...3..
+					...2..
+					.22xxx
+				`),
+				parseWell(t, `
+					......
+					...3..
+					.xxx..
+				`),
 			},
 		},
 	}
@@ -668,18 +1032,12 @@ func TestScoredCombo(t *testing.T) {
 	}
 }
 
-// transpose swaps values between columns and rows in a well,
-// as visually is more natural to put all X values per row in a single line
-// (as they appear on actual games)
-// and this can only be done by putting those in the Y index of the well type.
-func transpose(slice doric.Well) doric.Well {
-	xl := len(slice[0])
-	yl := len(slice)
-	result := doric.NewWell(xl, yl)
-	for i := 0; i < xl; i++ {
-		for j := 0; j < yl; j++ {
-			result[i][j] = slice[j][i]
-		}
+// parseWell returns the well written in the passed text, failing the test if it is not valid
+func parseWell(t *testing.T, text string) doric.Well {
+	t.Helper()
+	well, err := doric.ParseWell(text)
+	if err != nil {
+		t.Fatalf("Invalid well text: %v", err)
 	}
-	return result
+	return well
 }
@@ -0,0 +1,112 @@
+package doric
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// RecordingVersion is the version of the recording format written by this package
+const RecordingVersion = 1
+
+// Possible errors returned when loading a recording
+const (
+	errorUnsupportedRecordingVersion = "Unsupported recording version %d"
+	errorNegativeRecordingTicks      = "Ticks in recording must be equal or greater than 0"
+	errorRecordedCommandTick         = "Recorded commands must be in tick order, between 0 and the recording ticks"
+)
+
+// Recording holds everything needed to replay a game exactly as it was played: the seed
+// used to create its tileset builder, the initial well and the commands sent by the player,
+// indexed by the game tick in which they were processed.
+type Recording struct {
+	Version int   `json:"version"`
+	Seed    int64 `json:"seed"`
+	Well    Well  `json:"well"`
+	// Commands processed during the game, in order
+	Commands []RecordedCommand `json:"commands"`
+	// Total number of ticks the game lasted
+	Ticks int `json:"ticks"`
+}
+
+// RecordedCommand is a command processed during a recorded game.
+// Tick is the number of ticks elapsed in the game when the command was processed.
+type RecordedCommand struct {
+	Tick    int     `json:"tick"`
+	Command Command `json:"command"`
+}
+
+// NewRecording returns a new empty Recording for a game whose builder is created with the passed seed.
+// To record a game, set it in the Recording field of the game configuration.
+func NewRecording(seed int64) *Recording {
+	return &Recording{
+		Version: RecordingVersion,
+		Seed:    seed,
+	}
+}
+
+// Save writes the recording to the passed writer in JSON format
+func (r *Recording) Save(w io.Writer) error {
+	return json.NewEncoder(w).Encode(r)
+}
+
+// LoadRecording reads a recording in JSON format from the passed reader
+func LoadRecording(r io.Reader) (*Recording, error) {
+	rec := &Recording{}
+	if err := json.NewDecoder(r).Decode(rec); err != nil {
+		return nil, err
+	}
+	if rec.Version != RecordingVersion {
+		return nil, fmt.Errorf(errorUnsupportedRecordingVersion, rec.Version)
+	}
+	if err := validateRecording(rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// validateRecording checks that the ticks of the recording and its commands are consistent
+func validateRecording(rec *Recording) error {
+	if rec.Ticks < 0 {
+		return fmt.Errorf(errorNegativeRecordingTicks)
+	}
+	last := 0
+	for _, comm := range rec.Commands {
+		if comm.Tick < last || comm.Tick > rec.Ticks {
+			return fmt.Errorf(errorRecordedCommandTick)
+		}
+		last = comm.Tick
+	}
+	return nil
+}
+
+// Replay plays again the recorded game, returning all the events it produced.
+// The passed builder must be created from the recording seed the same way as in the original game,
+// e. g. NewRandomBuilder(rec.Seed), and the configuration must be the same as well.
+// As replays do not depend on time, elapsed time in the final EventGameOver is always 0.
+// Returns an error if the ticks of the recording and its commands are not consistent.
+func Replay(rec *Recording, builder TilesetBuilder, cfg Config) ([]Event, error) {
+	if err := validateRecording(rec); err != nil {
+		return nil, err
+	}
+	cfg.Recording = nil
+	cfg.Clock = NewManualClock(time.Time{})
+	engine, err := NewEngine(rec.Well, builder, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	events := engine.Start()
+	next := 0
+	for tick := 0; ; tick++ {
+		for next < len(rec.Commands) && rec.Commands[next].Tick == tick {
+			events = append(events, engine.Step(rec.Commands[next].Command)...)
+			next++
+		}
+		if engine.IsOver() || tick >= rec.Ticks {
+			return events, nil
+		}
+		events = append(events, engine.Tick()...)
+	}
+}
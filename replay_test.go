@@ -0,0 +1,143 @@
+package doric_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/svera/doric"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	const seed = 42
+	clock := doric.NewManualClock(time.Now())
+	rec := doric.NewRecording(seed)
+	cfg := defaultConfig()
+	cfg.NumColors = 3
+	cfg.Clock = clock
+	cfg.Recording = rec
+	commands := make(chan doric.Command)
+	events, err := doric.Play(doric.NewWell(doric.StandardWidth, doric.StandardHeight), doric.NewRandomBuilder(seed), cfg, commands)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	var played []doric.Event
+	done := make(chan struct{})
+	go func() {
+		for ev := range events {
+			played = append(played, ev)
+		}
+		close(done)
+	}()
+
+	sequence := []doric.Command{doric.CommandLeft, doric.CommandRotate, doric.CommandDrop, doric.CommandRight, doric.CommandRight}
+	for i := 0; i < 20; i++ {
+		commands <- sequence[i%len(sequence)]
+		clock.Tick()
+		clock.Tick()
+	}
+	commands <- doric.CommandQuit
+	<-done
+
+	var buf bytes.Buffer
+	if err := rec.Save(&buf); err != nil {
+		t.Fatalf(err.Error())
+	}
+	loaded, err := doric.LoadRecording(&buf)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if loaded.Ticks != 40 || len(loaded.Commands) != 21 {
+		t.Errorf("Expected 40 ticks and 21 commands recorded, got %d and %d", loaded.Ticks, len(loaded.Commands))
+	}
+
+	cfg.Clock = nil
+	cfg.Recording = nil
+	replayed, err := doric.Replay(loaded, doric.NewRandomBuilder(loaded.Seed), cfg)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if !reflect.DeepEqual(withoutGameOver(played), withoutGameOver(replayed)) {
+		t.Errorf("Expected replayed events to be the same as the played ones")
+	}
+}
+
+func TestLoadRecordingErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{
+			name: "Must return error if version is not supported",
+			data: `{"version": 0}`,
+		},
+		{
+			name: "Must return error if ticks are negative",
+			data: `{"version": 1, "ticks": -1}`,
+		},
+		{
+			name: "Must return error if commands are not in tick order",
+			data: `{"version": 1, "ticks": 10, "commands": [{"tick": 5, "command": 0}, {"tick": 3, "command": 1}]}`,
+		},
+		{
+			name: "Must return error if a command is after the last tick",
+			data: `{"version": 1, "ticks": 10, "commands": [{"tick": 11, "command": 0}]}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := doric.LoadRecording(bytes.NewBufferString(test.data)); err == nil {
+				t.Errorf("Expected error when loading %s", test.data)
+			}
+		})
+	}
+}
+
+func TestReplayInvalidRecording(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*doric.Recording)
+	}{
+		{
+			name:   "Must return error if ticks are negative",
+			modify: func(r *doric.Recording) { r.Ticks = -1 },
+		},
+		{
+			name: "Must return error if commands are not in tick order",
+			modify: func(r *doric.Recording) {
+				r.Ticks = 10
+				r.Commands = []doric.RecordedCommand{
+					{Tick: 5, Command: doric.CommandLeft},
+					{Tick: 3, Command: doric.CommandRight},
+					{Tick: 6, Command: doric.CommandLeft},
+				}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := doric.NewRecording(1)
+			rec.Well = doric.NewWell(doric.StandardWidth, doric.StandardHeight)
+			test.modify(rec)
+			if _, err := doric.Replay(rec, doric.NewSequenceBuilder([]int{1, 1, 1}), defaultConfig()); err == nil {
+				t.Errorf("Expected error replaying an invalid recording")
+			}
+		})
+	}
+}
+
+// withoutGameOver removes EventGameOver from the passed events, as elapsed time
+// differs between a game and its replay
+func withoutGameOver(events []doric.Event) []doric.Event {
+	var filtered []doric.Event
+	for _, ev := range events {
+		if _, ok := ev.(doric.EventGameOver); !ok {
+			filtered = append(filtered, ev)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,37 @@
+package doric
+
+// Scorer calculates the points awarded to the player during a game
+type Scorer interface {
+	// Scored returns the points awarded for removing tiles in a single step of a chain.
+	// Combo is 1 for the first step of the chain, 2 for the second one and so on.
+	Scored(removed, combo, level int) int
+	// Dropped returns the points awarded when a column lands, for the cells the player
+	// moved it down manually.
+	Dropped(cells, level int) int
+}
+
+// SegaScorer implements a Scorer modelled on the rules of commercial SEGA versions, in which
+// removed tiles are multiplied by the combo and the level, and every cell a column is moved down
+// by the player earns a small bonus.
+type SegaScorer struct {
+	// Points awarded for every removed tile
+	PointsPerTile int
+	// Points awarded for every cell a column is moved down by the player
+	PointsPerDroppedCell int
+}
+
+// DefaultScorer is the Scorer used if none is set in the game configuration
+var DefaultScorer = SegaScorer{
+	PointsPerTile:        30,
+	PointsPerDroppedCell: 1,
+}
+
+// Scored returns the points awarded for removing tiles in a single step of a chain
+func (s SegaScorer) Scored(removed, combo, level int) int {
+	return removed * s.PointsPerTile * combo * level
+}
+
+// Dropped returns the points awarded for the cells a column was moved down by the player
+func (s SegaScorer) Dropped(cells, level int) int {
+	return cells * s.PointsPerDroppedCell
+}
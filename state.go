@@ -0,0 +1,183 @@
+package doric
+
+import "fmt"
+
+// Possible errors returned when resuming a game from an invalid state
+const (
+	errorLessEqualZeroStateLevel = "Level in game state must be greater than 0"
+	errorLessEqualZeroStateSpeed = "Speed in game state must be greater than 0"
+	errorNegativeStateDrawn      = "Drawn in game state must be equal or greater than 0"
+	errorNegativeStateStartLevel = "StartLevel in game state must be equal or greater than 0"
+	errorNegativeStateDelay      = "Delay in game state must be equal or greater than 0"
+	errorLessEqualZeroStateDelay = "Delay in game state must be greater than 0 while clearing or spawning"
+	errorStateClearingSpawning   = "Game state cannot be clearing and spawning at the same time"
+	errorStateColumnOutOfBounds  = "Column in game state must be inside the well"
+)
+
+// GameState is a snapshot of a game in progress, which can be serialised (e. g. with encoding/json
+// or encoding/gob) and used later to resume the game from the same point.
+type GameState struct {
+	Well         Well    `json:"well"`
+	Column       Column  `json:"column"`
+	NextTileset  []int   `json:"nextTileset"`
+	Level        int     `json:"level"`
+	Speed        float64 `json:"speed"`
+	TotalRemoved int     `json:"totalRemoved"`
+	MaxCombo     int     `json:"maxCombo"`
+	Score        int     `json:"score"`
+	// Dropped is the number of cells the current column was moved down by the player
+	Dropped int `json:"dropped"`
+	// TouchedDown is true if the current column cannot fall further and its lock delay is running,
+	// with LockTicks ticks left and LockResets resets already used
+	TouchedDown bool `json:"touchedDown"`
+	LockTicks   int  `json:"lockTicks"`
+	LockResets  int  `json:"lockResets"`
+	// Clearing is true if marked tiles are waiting to be removed from the well, and Spawning is true
+	// if a new column is waiting to enter it, with Delay ticks left in both cases.
+	// Combo is the current step of the chain being resolved.
+	Clearing bool `json:"clearing"`
+	Spawning bool `json:"spawning"`
+	Delay    int  `json:"delay"`
+	Combo    int  `json:"combo"`
+	// Drawn is the number of tilesets built so far. When resuming a game, the builder is called that
+	// many times before the game starts, so a deterministic builder (e. g. one created from the same seed)
+	// will keep returning the same sequence of tilesets as in the original game.
+	Drawn int `json:"drawn"`
+	// StartLevel is the level the game started at, as the tiles needed to reach it count towards the next level.
+	// If 0, the game started at level 1.
+	StartLevel int `json:"startLevel"`
+}
+
+// State returns a snapshot of the current state of the game
+func (e *Engine) State() GameState {
+	return GameState{
+		Well:         e.well.Clone(),
+		Column:       e.column.copy(),
+		NextTileset:  copyTileset(e.nextTileset),
+		Level:        e.level,
+		Speed:        e.speed,
+		TotalRemoved: e.totalRemoved,
+		MaxCombo:     e.maxCombo,
+		Score:        e.score,
+		Dropped:      e.dropped,
+		TouchedDown:  e.touchedDown,
+		LockTicks:    e.lockTicks,
+		LockResets:   e.lockResets,
+		Clearing:     e.phase == phaseClearing,
+		Spawning:     e.phase == phaseSpawning,
+		Delay:        e.delay,
+		Combo:        e.combo,
+		Drawn:        e.drawn,
+		StartLevel:   e.startLevel,
+	}
+}
+
+// NewEngineFromState returns a new Engine instance which will resume the game from the passed state,
+// or an error if the passed configuration or state are not valid.
+// The passed builder is fast-forwarded as many tilesets as were built in the original game.
+func NewEngineFromState(state GameState, build TilesetBuilder, cfg Config) (*Engine, error) {
+	if err := validateConfig(cfg); err != nil {
+		return nil, err
+	}
+	if err := validateState(state, cfg); err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < state.Drawn; i++ {
+		build(cfg.numColors(), cfg.columnLength())
+	}
+	e := &Engine{
+		resumed: true,
+		cfg:     cfg,
+		scorer:  cfg.scorer(),
+		clock:   cfg.clock(),
+		build:   build,
+	}
+	e.restore(state)
+	return e, nil
+}
+
+// restore puts the game in the passed state
+func (e *Engine) restore(state GameState) {
+	column := state.Column.copy()
+	e.well = state.Well.Clone()
+	e.column = &column
+	e.nextTileset = copyTileset(state.NextTileset)
+	e.level = state.Level
+	e.speed = state.Speed
+	e.totalRemoved = state.TotalRemoved
+	e.maxCombo = state.MaxCombo
+	e.score = state.Score
+	e.dropped = state.Dropped
+	e.touchedDown = state.TouchedDown
+	e.lockTicks = state.LockTicks
+	e.lockResets = state.LockResets
+	e.phase = phaseFalling
+	if state.Clearing {
+		e.phase = phaseClearing
+	} else if state.Spawning {
+		e.phase = phaseSpawning
+	}
+	e.delay = state.Delay
+	e.combo = state.Combo
+	e.drawn = state.Drawn
+	e.startLevel = state.StartLevel
+	if e.startLevel == 0 {
+		e.startLevel = 1
+	}
+}
+
+func validateState(state GameState, cfg Config) error {
+	if state.Level <= 0 {
+		return fmt.Errorf(errorLessEqualZeroStateLevel)
+	}
+	if state.Speed <= 0 {
+		return fmt.Errorf(errorLessEqualZeroStateSpeed)
+	}
+	if state.Drawn < 0 {
+		return fmt.Errorf(errorNegativeStateDrawn)
+	}
+	if state.StartLevel < 0 {
+		return fmt.Errorf(errorNegativeStateStartLevel)
+	}
+	if state.Delay < 0 {
+		return fmt.Errorf(errorNegativeStateDelay)
+	}
+	if state.Clearing && state.Spawning {
+		return fmt.Errorf(errorStateClearingSpawning)
+	}
+	if (state.Clearing || state.Spawning) && state.Delay == 0 {
+		return fmt.Errorf(errorLessEqualZeroStateDelay)
+	}
+	if err := state.Well.validate(state.Clearing); err != nil {
+		return err
+	}
+	if !state.Well.inBounds(state.Column.X, state.Column.Y) {
+		return fmt.Errorf(errorStateColumnOutOfBounds)
+	}
+	for _, tileset := range [][]int{state.Column.Tileset, state.NextTileset} {
+		if err := validateTileset(tileset, cfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Possible errors returned when a tileset is not valid for the game configuration
+const (
+	errorTilesetLength  = "Tilesets must have ColumnLength tiles"
+	errorTileOutOfRange = "Tiles of tilesets must be between 1 and NumColors, or MagicJewel"
+)
+
+// validateTileset checks that the passed tileset has the length and colors set in the configuration
+func validateTileset(tileset []int, cfg Config) error {
+	if len(tileset) != cfg.columnLength() {
+		return fmt.Errorf(errorTilesetLength)
+	}
+	for _, tile := range tileset {
+		if (tile < 1 || tile > cfg.numColors()) && tile != MagicJewel {
+			return fmt.Errorf(errorTileOutOfRange)
+		}
+	}
+	return nil
+}
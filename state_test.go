@@ -0,0 +1,308 @@
+package doric_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/svera/doric"
+)
+
+func TestSnapshot(t *testing.T) {
+	tilesets := [][]int{{1, 2, 3}, {4, 5, 6}, {2, 3, 4}, {3, 4, 5}}
+	engine := newEngine(t, defaultConfig(), doric.NewWell(doric.StandardWidth, doric.StandardHeight), tilesets)
+	engine.Start()
+	engine.Step(doric.CommandDrop)
+	engine.Step(doric.CommandLeft)
+	engine.Step(doric.CommandDown)
+
+	events := engine.Step(doric.CommandSnapshot)
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event but got %d", len(events))
+	}
+	snapshot, ok := events[0].(doric.EventSnapshot)
+	if !ok {
+		t.Fatalf("Expected an EventSnapshot but got %T", events[0])
+	}
+
+	data, err := json.Marshal(snapshot.State)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	var state doric.GameState
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if !reflect.DeepEqual(state, snapshot.State) {
+		t.Errorf("Expected state %v after serialisation but got %v", snapshot.State, state)
+	}
+
+	resumed, err := doric.NewEngineFromState(state, (&mockTilesetBuilder{Tilesets: tilesets}).build, defaultConfig())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	events = resumed.Start()
+	renewed, ok := events[0].(doric.EventRenewed)
+	if !ok {
+		t.Fatalf("Expected an EventRenewed but got %T", events[0])
+	}
+	expected := doric.Column{Tileset: []int{4, 5, 6}, X: 2, Y: 1}
+	if !reflect.DeepEqual(renewed.Column, expected) {
+		t.Errorf("Expected column %v but got %v", expected, renewed.Column)
+	}
+	if !reflect.DeepEqual(renewed.Well, state.Well) {
+		t.Errorf("Expected well %v but got %v", state.Well, renewed.Well)
+	}
+
+	events = resumed.Step(doric.CommandDrop)
+	renewed = events[len(events)-1].(doric.EventRenewed)
+	if !reflect.DeepEqual(renewed.NextTileset, []int{3, 4, 5}) {
+		t.Errorf("Expected builder to be fast-forwarded and return %v, got %v", []int{3, 4, 5}, renewed.NextTileset)
+	}
+}
+
+func TestResumeContext(t *testing.T) {
+	engine := newEngine(t, defaultConfig(), doric.NewWell(doric.StandardWidth, doric.StandardHeight), [][]int{{1, 2, 3}})
+	engine.Start()
+	state := engine.State()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	factory := &mockTilesetBuilder{
+		Tilesets: [][]int{{1, 2, 3}},
+	}
+	events, err := doric.ResumeContext(ctx, state, factory.build, defaultConfig(), make(chan doric.Command))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	cancel()
+
+	// Give the game time to give up delivering its final event
+	time.Sleep(200 * time.Millisecond)
+	select {
+	case _, open := <-events:
+		if open {
+			t.Errorf("Expected events channel to be closed")
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Game goroutine should have finished")
+	}
+}
+
+func TestResumeInvalidState(t *testing.T) {
+	valid := func() doric.GameState {
+		return doric.GameState{
+			Well:        doric.NewWell(doric.StandardWidth, doric.StandardHeight),
+			Column:      doric.Column{Tileset: []int{1, 2, 3}, X: 3},
+			NextTileset: []int{4, 5, 6},
+			Level:       1,
+			Speed:       1,
+		}
+	}
+	tests := []struct {
+		name   string
+		modify func(*doric.GameState)
+	}{
+		{
+			name:   "Must return error if level is 0",
+			modify: func(s *doric.GameState) { s.Level = 0 },
+		},
+		{
+			name:   "Must return error if column is left of the well",
+			modify: func(s *doric.GameState) { s.Column.X = -1 },
+		},
+		{
+			name:   "Must return error if column is right of the well",
+			modify: func(s *doric.GameState) { s.Column.X = 40 },
+		},
+		{
+			name:   "Must return error if column is above the well",
+			modify: func(s *doric.GameState) { s.Column.Y = -1 },
+		},
+		{
+			name:   "Must return error if column is below the well",
+			modify: func(s *doric.GameState) { s.Column.Y = doric.StandardHeight },
+		},
+		{
+			name:   "Must return error if column has no tiles",
+			modify: func(s *doric.GameState) { s.Column.Tileset = nil },
+		},
+		{
+			name:   "Must return error if next tileset is too long",
+			modify: func(s *doric.GameState) { s.NextTileset = []int{1, 2, 3, 4} },
+		},
+		{
+			name:   "Must return error if column has tiles out of range",
+			modify: func(s *doric.GameState) { s.Column.Tileset = []int{1, 7, 3} },
+		},
+		{
+			name:   "Must return error if next tileset has empty tiles",
+			modify: func(s *doric.GameState) { s.NextTileset = []int{1, doric.Empty, 3} },
+		},
+		{
+			name:   "Must return error if spawning with no delay left",
+			modify: func(s *doric.GameState) { s.Spawning = true },
+		},
+		{
+			name:   "Must return error if clearing with no delay left",
+			modify: func(s *doric.GameState) { s.Clearing = true },
+		},
+		{
+			name: "Must return error if clearing and spawning at the same time",
+			modify: func(s *doric.GameState) {
+				s.Clearing = true
+				s.Spawning = true
+				s.Delay = 1
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := valid()
+			test.modify(&state)
+			factory := &mockTilesetBuilder{
+				Tilesets: [][]int{{1, 2, 3}},
+			}
+			if _, err := doric.Resume(state, factory.build, defaultConfig(), make(chan doric.Command)); err == nil {
+				t.Errorf("Expected error when resuming from an invalid state")
+			}
+		})
+	}
+
+	t.Run("Must accept magic columns", func(t *testing.T) {
+		state := valid()
+		state.Column.Tileset = []int{doric.MagicJewel, doric.MagicJewel, doric.MagicJewel}
+		factory := &mockTilesetBuilder{
+			Tilesets: [][]int{{1, 2, 3}},
+		}
+		if _, err := doric.NewEngineFromState(state, factory.build, defaultConfig()); err != nil {
+			t.Errorf("Expected no error but got %v", err)
+		}
+	})
+}
+
+func TestResumeWellMarkers(t *testing.T) {
+	state := doric.GameState{
+		Well: parseWell(t, `
+			......
+			...x..
+			..1x2.
+		`),
+		Column:      doric.Column{Tileset: []int{1, 2, 3}},
+		NextTileset: []int{4, 5, 6},
+		Level:       1,
+		Speed:       1,
+	}
+	factory := &mockTilesetBuilder{
+		Tilesets: [][]int{{1, 2, 3}},
+	}
+	if _, err := doric.NewEngineFromState(state, factory.build, defaultConfig()); !errors.Is(err, doric.ErrRemoveMarker) {
+		t.Errorf("Expected error %v when resuming a game not clearing tiles, got %v", doric.ErrRemoveMarker, err)
+	}
+	state.Clearing = true
+	state.Delay = 1
+	if _, err := doric.NewEngineFromState(state, factory.build, defaultConfig()); err != nil {
+		t.Errorf("Expected no error when resuming a game clearing tiles, got %v", err)
+	}
+}
+
+func TestResumeWhileClearing(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.ClearDelay = 2
+	cfg.SpawnDelay = 1
+	well := parseWell(t, `
+		......
+		......
+		.22.11
+	`)
+	tilesets := [][]int{{1, 2, 3}, {4, 5, 6}}
+	engine := newEngine(t, cfg, well, tilesets)
+	engine.Start()
+	engine.Step(doric.CommandDown)
+	engine.Step(doric.CommandDown)
+	engine.Tick()
+	clearing := engine.State()
+	for i := 0; i < 4; i++ {
+		engine.Tick()
+	}
+	spawning := engine.State()
+
+	t.Run("Must not present the landed column as the falling one while clearing", func(t *testing.T) {
+		resumed, err := doric.NewEngineFromState(clearing, (&mockTilesetBuilder{Tilesets: tilesets}).build, cfg)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		events := resumed.Start()
+		if len(events) != 1 {
+			t.Fatalf("Expected 1 event but got %v", events)
+		}
+		started, ok := events[0].(doric.EventClearStarted)
+		if !ok {
+			t.Fatalf("Expected an EventClearStarted but got %T", events[0])
+		}
+		if started.Delay != clearing.Delay || started.Combo != clearing.Combo {
+			t.Errorf("Expected delay %d and combo %d but got %d and %d", clearing.Delay, clearing.Combo, started.Delay, started.Combo)
+		}
+		renewed := 0
+		for i := 0; i < 5; i++ {
+			renewed += len(withKinds(resumed.Tick(), doric.KindRenewed))
+		}
+		if renewed != 1 {
+			t.Errorf("Expected 1 EventRenewed but got %d", renewed)
+		}
+	})
+
+	t.Run("Must send no event until the new column enters the well while spawning", func(t *testing.T) {
+		if !spawning.Spawning {
+			t.Fatalf("Expected game to be waiting for a new column")
+		}
+		resumed, err := doric.NewEngineFromState(spawning, (&mockTilesetBuilder{Tilesets: tilesets}).build, cfg)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if events := resumed.Start(); len(events) != 0 {
+			t.Errorf("Expected no events but got %v", events)
+		}
+		events := resumed.Tick()
+		if len(events) != 1 || events[0].Kind() != doric.KindRenewed {
+			t.Errorf("Expected an EventRenewed but got %v", events)
+		}
+	})
+}
+
+func TestResumeStartLevel(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.NumberTilesForNextLevel = 3
+	cfg.StartLevel = 3
+	well := parseWell(t, `
+		......
+		......
+		.22.11
+	`)
+	tilesets := [][]int{{1, 2, 3}, {4, 5, 6}}
+	engine := newEngine(t, cfg, well, tilesets)
+	engine.Start()
+	state := engine.State()
+
+	cfg.StartLevel = 0
+	resumed, err := doric.NewEngineFromState(state, (&mockTilesetBuilder{Tilesets: tilesets}).build, cfg)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	resumed.Start()
+	resumed.Step(doric.CommandDown)
+	resumed.Step(doric.CommandDown)
+
+	events := withKinds(resumed.Tick(), doric.KindScored)
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 EventScored but got %v", events)
+	}
+	for i, level := range []int{4, 5} {
+		if scored := events[i].(doric.EventScored); scored.Level != level {
+			t.Errorf("Expected level %d but got %d", level, scored.Level)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package doric
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Possible errors returned when parsing a well
+const (
+	errorEmptyWellText     = "Well text must have at least one row"
+	errorRaggedWellText    = "All rows in well text must have the same width"
+	errorUnknownTileSymbol = "Unknown tile symbol in well text"
+	errorUnprintableTile   = "Well holds a tile which cannot be written as text"
+)
+
+// Symbols used to write wells as text, besides digits for colors
+const (
+	symbolEmpty      = '.'
+	symbolRemove     = 'x'
+	symbolMagicJewel = '*'
+)
+
+// String returns the well as a grid of text, as it looks on screen: one line per row from top to bottom,
+// with '.' for empty cells, '1' to '9' for tile colors, 'x' for tiles to be removed and '*' for magic jewels.
+// Invalid tiles, and missing cells in wells whose columns have different heights, are written as '?'.
+func (p Well) String() string {
+	height := 0
+	for x := range p {
+		if len(p[x]) > height {
+			height = len(p[x])
+		}
+	}
+	var b strings.Builder
+	for y := 0; y < height; y++ {
+		if y > 0 {
+			b.WriteByte('\n')
+		}
+		for x := 0; x < p.Width(); x++ {
+			symbol := byte('?')
+			if y < len(p[x]) {
+				if s, ok := tileSymbol(p[x][y]); ok {
+					symbol = s
+				}
+			}
+			b.WriteByte(symbol)
+		}
+	}
+	return b.String()
+}
+
+// MarshalText implements encoding.TextMarshaler, writing the well as String does.
+// Returns an error if the well holds invalid tiles or its columns have different heights.
+func (p Well) MarshalText() ([]byte, error) {
+	for x := range p {
+		if len(p[x]) != p.Height() {
+			return nil, ErrWellNotRectangular
+		}
+		for y := range p[x] {
+			if _, ok := tileSymbol(p[x][y]); !ok {
+				return nil, fmt.Errorf(errorUnprintableTile)
+			}
+		}
+	}
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, reading the well as ParseWell does
+func (p *Well) UnmarshalText(text []byte) error {
+	well, err := ParseWell(string(text))
+	if err != nil {
+		return err
+	}
+	*p = well
+	return nil
+}
+
+// ParseWell returns the well written in the passed text, in the format returned by Well.String.
+// Leading and trailing blank lines and spaces around every row are ignored, so wells can be written
+// as indented raw string literals.
+func ParseWell(text string) (Well, error) {
+	var rows []string
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		rows = append(rows, strings.TrimSpace(line))
+	}
+	if rows[0] == "" {
+		return nil, fmt.Errorf(errorEmptyWellText)
+	}
+
+	well := NewWell(len(rows[0]), len(rows))
+	for y, row := range rows {
+		if len(row) != well.Width() {
+			return nil, fmt.Errorf(errorRaggedWellText)
+		}
+		for x := 0; x < len(row); x++ {
+			tile, ok := symbolTile(row[x])
+			if !ok {
+				return nil, fmt.Errorf("%s: %q", errorUnknownTileSymbol, row[x])
+			}
+			well[x][y] = tile
+		}
+	}
+	return well, nil
+}
+
+// tileSymbol returns the symbol which represents the passed tile, or false if it is not valid
+func tileSymbol(tile int) (byte, bool) {
+	switch {
+	case tile == Empty:
+		return symbolEmpty, true
+	case tile == Remove:
+		return symbolRemove, true
+	case tile == MagicJewel:
+		return symbolMagicJewel, true
+	case tile > Empty && tile <= MaxNumColors:
+		return byte('0' + tile), true
+	}
+	return 0, false
+}
+
+// symbolTile returns the tile represented by the passed symbol, or false if it is not valid
+func symbolTile(symbol byte) (int, bool) {
+	switch {
+	case symbol == symbolEmpty:
+		return Empty, true
+	case symbol == symbolRemove:
+		return Remove, true
+	case symbol == symbolMagicJewel:
+		return MagicJewel, true
+	case symbol >= '1' && symbol <= '0'+MaxNumColors:
+		return int(symbol - '0'), true
+	}
+	return 0, false
+}
@@ -0,0 +1,115 @@
+package doric_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/svera/doric"
+)
+
+func TestWellString(t *testing.T) {
+	well := doric.NewWell(4, 3)
+	well.Set(0, 2, 1)
+	well.Set(1, 2, 9)
+	well.Set(1, 1, doric.Remove)
+	well.Set(3, 2, doric.MagicJewel)
+
+	expected := "....\n.x..\n19.*"
+	if well.String() != expected {
+		t.Errorf("Expected well text %q but got %q", expected, well.String())
+	}
+}
+
+func TestWellStringNotRectangular(t *testing.T) {
+	well := doric.Well{{0, 0, 0}, {0}, {1, 2, 3}}
+	expected := "..1\n.?2\n.?3"
+	if well.String() != expected {
+		t.Errorf("Expected well text %q but got %q", expected, well.String())
+	}
+	if _, err := well.MarshalText(); !errors.Is(err, doric.ErrWellNotRectangular) {
+		t.Errorf("Expected error %v but got %v", doric.ErrWellNotRectangular, err)
+	}
+}
+
+func TestParseWell(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected doric.Well
+		wantErr  bool
+	}{
+		{
+			name: "Must parse an indented well",
+			text: `
+				...
+				.x.
+				2*1
+			`,
+			// Wells are indexed by column first
+			expected: doric.Well{
+				{0, 0, 2},
+				{0, doric.Remove, doric.MagicJewel},
+				{0, 0, 1},
+			},
+		},
+		{
+			name:    "Must return error if text is empty",
+			text:    "  \n ",
+			wantErr: true,
+		},
+		{
+			name:    "Must return error if rows have different widths",
+			text:    "...\n..\n...",
+			wantErr: true,
+		},
+		{
+			name:    "Must return error if a symbol is unknown",
+			text:    "...\n.0.",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			well, err := doric.ParseWell(test.text)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("Expected error parsing %q", test.text)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got %v", err)
+			}
+			if !reflect.DeepEqual(well, test.expected) {
+				t.Errorf("Expected well %v but got %v", test.expected, well)
+			}
+		})
+	}
+}
+
+func TestWellMarshalText(t *testing.T) {
+	well := parseWell(t, `
+		......
+		..x...
+		.12*34
+	`)
+	data, err := json.Marshal(well)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	var decoded doric.Well
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	if !reflect.DeepEqual(well, decoded) {
+		t.Errorf("Expected well %v but got %v", well, decoded)
+	}
+
+	well[0][0] = doric.MagicJewel + 1
+	if _, err := well.MarshalText(); err == nil {
+		t.Errorf("Expected error marshaling a well with invalid tiles")
+	}
+}
@@ -1,23 +1,45 @@
 package doric
 
-// Values that represent empty or removable tiles in the well
+import (
+	"errors"
+	"fmt"
+)
+
+// Values that represent empty or removable tiles in the well.
+// Remove marks a tile which is part of a match and is about to be removed. Wells holding it
+// are only sent in events during a chain, or returned by a Well method before being settled.
+// Any other value is a tile color between 1 and MaxNumColors, or MagicJewel.
 const (
 	Remove = -1
 	Empty  = 0
 )
 
+// MinWellWidth is the minimum width of a well, so columns can be moved at both sides of the one where they enter
+const MinWellWidth = 3
+
+// Errors returned when validating a well
+var (
+	ErrWellTooNarrow      = fmt.Errorf("Well must be at least %d tiles wide", MinWellWidth)
+	ErrWellTooShort       = errors.New("Well must be at least 1 tile high")
+	ErrWellNotRectangular = errors.New("All well columns must have the same height")
+	ErrInvalidTile        = errors.New("Well tiles must be Empty or a color between 1 and MaxNumColors")
+	ErrFloatingTile       = errors.New("Well tiles must not have empty cells below")
+	ErrRemoveMarker       = errors.New("Well must not hold Remove markers")
+)
+
+// Possible errors returned when accessing the well
+const (
+	errorOutOfBounds  = "Coordinates out of well bounds"
+	errorInvalidTile  = "Tile must be Remove, Empty, a color between 1 and MaxNumColors or MagicJewel"
+	errorInvalidMatch = "Minimum match length must be 0 or greater than 1"
+)
+
 // Standard Well dimensions (in tiles) as per commercial SEGA versions
 const (
 	StandardWidth  = 6
 	StandardHeight = 13
 )
 
-// coords represent the coordinates of a tile or cell in the well
-type coords struct {
-	x int
-	y int
-}
-
 // Well is a slice of slices which represents the field of play, holding the tiles that are falling.
 // First index represents tiles in the X (horizontal) axis, second index refers to the Y (vertical) axis.
 type Well [][]int
@@ -32,92 +54,230 @@ func NewWell(width, height int) Well {
 	return p
 }
 
+// steps holds the offset between two consecutive cells of a line in every direction
+var steps = [...]Position{
+	DirectionHorizontal:   {1, 0},
+	DirectionVertical:     {0, 1},
+	DirectionDiagonalDown: {1, 1},
+	DirectionDiagonalUp:   {1, -1},
+}
+
 // markTilesToRemove scans well lines looking for tiles to be removed, amd mark those tiles.
-// Tiles repeated in 3 or more consecutive positions horizontally, vertically or diagonally are to be removed.
-func (p Well) markTilesToRemove() int {
-	remove := map[coords]struct{}{}
-	p.checkHorizontalLines(remove)
-	p.checkVerticalLines(remove)
-	p.checkDiagonalLines(remove)
-	for coords := range remove {
-		// Cells with negative values are cells with tiles to be removed
-		p[coords.x][coords.y] = Remove
-	}
-	return len(remove)
-}
-
-func (p Well) checkHorizontalLines(remove map[coords]struct{}) {
-	for y := p.height() - 1; y >= 0; y-- {
-		for x := 0; x < p.width()-2; x++ {
-			if p[x][y] == Empty || p[x][y] == Remove {
-				continue
-			}
-			if p[x][y] == p[x+1][y] && p[x+1][y] == p[x+2][y] {
-				remove[coords{x, y}] = struct{}{}
-				remove[coords{x + 1, y}] = struct{}{}
-				remove[coords{x + 2, y}] = struct{}{}
+// Tiles repeated in minMatch or more consecutive positions horizontally, vertically or diagonally are to be removed.
+// Returns how many tiles were marked and the lines they belong to.
+func (p Well) markTilesToRemove(minMatch int) (int, []Match) {
+	var matches []Match
+	for dir := range steps {
+		matches = p.checkLines(Direction(dir), minMatch, matches)
+	}
+	removed := 0
+	for _, match := range matches {
+		for _, pos := range match.Cells {
+			// Cells with negative values are cells with tiles to be removed
+			if p[pos.X][pos.Y] != Remove {
+				p[pos.X][pos.Y] = Remove
+				removed++
 			}
 		}
 	}
+	return removed, matches
 }
 
-func (p Well) checkVerticalLines(remove map[coords]struct{}) {
-	for x := 0; x < p.width(); x++ {
-		for y := p.height() - 1; y > 1; y-- {
-			if p[x][y] == Empty || p[x][y] == Remove {
-				break
+// checkLines looks for lines of minMatch or more equal tiles in the passed direction,
+// appending them to the passed matches
+func (p Well) checkLines(dir Direction, minMatch int, matches []Match) []Match {
+	step := steps[dir]
+	for x := 0; x < p.Width(); x++ {
+		for y := 0; y < len(p[x]); y++ {
+			tile := p[x][y]
+			if tile == Empty || tile == Remove || tile == MagicJewel {
+				continue
+			}
+			// Lines are only counted from their first tile
+			if p.inBounds(x-step.X, y-step.Y) && p[x-step.X][y-step.Y] == tile {
+				continue
 			}
-			if p[x][y] == p[x][y-1] && p[x][y-1] == p[x][y-2] {
-				remove[coords{x, y}] = struct{}{}
-				remove[coords{x, y - 1}] = struct{}{}
-				remove[coords{x, y - 2}] = struct{}{}
+			length := 1
+			for p.inBounds(x+length*step.X, y+length*step.Y) && p[x+length*step.X][y+length*step.Y] == tile {
+				length++
 			}
+			if length < minMatch {
+				continue
+			}
+			match := Match{Direction: dir, Length: length, Color: tile}
+			for i := 0; i < length; i++ {
+				match.Cells = append(match.Cells, Position{x + i*step.X, y + i*step.Y})
+			}
+			matches = append(matches, match)
 		}
 	}
+	return matches
 }
 
-func (p Well) checkDiagonalLines(remove map[coords]struct{}) {
-	for y := p.height() - 1; y > 1; y-- {
-		// Checks for tiles to be removed in diagonal / lines
-		for x := 0; x < p.width()-2 && y > 1; x++ {
-			if p[x][y] == Empty || p[x][y] == Remove {
+// inBounds returns true if the passed coordinates are inside the well.
+// The height of the passed column is used, as columns may have different heights in invalid wells.
+func (p Well) inBounds(x, y int) bool {
+	return x >= 0 && x < p.Width() && y >= 0 && y < len(p[x])
+}
+
+// markColor marks to be removed all tiles in the well with the same color as the one
+// right underneath the passed magic column, as well as the column itself.
+// Returns the destroyed color (Empty if the column landed on the floor) and how many tiles of that color were marked.
+func (p Well) markColor(pc *Column) (int, int) {
+	color := Empty
+	if p.inBounds(pc.X, pc.Y+1) {
+		color = p[pc.X][pc.Y+1]
+	}
+	removed := 0
+	for x := range p {
+		for y := range p[x] {
+			if p[x][y] == MagicJewel {
+				p[x][y] = Remove
 				continue
 			}
-			if p[x][y] == p[x+1][y-1] && p[x+1][y-1] == p[x+2][y-2] {
-				remove[coords{x, y}] = struct{}{}
-				remove[coords{x + 1, y - 1}] = struct{}{}
-				remove[coords{x + 2, y - 2}] = struct{}{}
+			if color != Empty && p[x][y] == color {
+				p[x][y] = Remove
+				removed++
 			}
 		}
-		// Checks for tiles to be removed in diagonal \ lines
-		for x := p.width() - 1; x > 1 && y > 1; x-- {
-			if p[x][y] == Empty {
-				continue
-			}
-			if p[x][y] == p[x-1][y-1] && p[x-1][y-1] == p[x-2][y-2] {
-				remove[coords{x, y}] = struct{}{}
-				remove[coords{x - 1, y - 1}] = struct{}{}
-				remove[coords{x - 2, y - 2}] = struct{}{}
+	}
+	return color, removed
+}
+
+// LandingY returns the vertical position the passed column would have in the well
+// if it kept falling until it could not fall further.
+// If the column is outside the well, its vertical position is returned unchanged.
+func (p Well) LandingY(col Column) int {
+	if !p.inBounds(col.X, col.Y) {
+		return col.Y
+	}
+	for col.down(p) {
+	}
+	return col.Y
+}
+
+// Validate returns an error if the well cannot be used to start a game: if it is narrower than MinWellWidth,
+// has no height, its columns have different heights, or it holds Remove markers, magic jewels, invalid tiles
+// or tiles over empty cells. Errors wrap one of the exported ErrWell* or Err*Tile errors, and tell where the
+// offending tile is, if any.
+func (p Well) Validate() error {
+	return p.validate(false)
+}
+
+// validate works as Validate, but accepts Remove markers if markers is true,
+// as in wells of games stopped while removing tiles
+func (p Well) validate(markers bool) error {
+	if p.Width() < MinWellWidth {
+		return ErrWellTooNarrow
+	}
+	if p.Height() < 1 {
+		return ErrWellTooShort
+	}
+	for x := range p {
+		if len(p[x]) != p.Height() {
+			return ErrWellNotRectangular
+		}
+		empty := false
+		for y := p.Height() - 1; y >= 0; y-- {
+			tile := p[x][y]
+			switch {
+			case tile == Remove && !markers:
+				return fmt.Errorf("%w at (%d, %d)", ErrRemoveMarker, x, y)
+			case tile < Remove || tile > MaxNumColors:
+				return fmt.Errorf("%w at (%d, %d)", ErrInvalidTile, x, y)
+			case tile == Empty:
+				empty = true
+			case empty:
+				return fmt.Errorf("%w at (%d, %d)", ErrFloatingTile, x, y)
 			}
 		}
 	}
+	return nil
 }
 
 // Width returns well's width
-func (p Well) width() int {
+func (p Well) Width() int {
 	return len(p)
 }
 
-// Height returns well's height
-func (p Well) height() int {
+// Height returns well's height, which is 0 if the well has no width
+func (p Well) Height() int {
+	if len(p) == 0 {
+		return 0
+	}
 	return len(p[0])
 }
 
-// settle moves down all tiles which have empty cells below
-func (p Well) settle() {
-	for x := 0; x < p.width(); x++ {
+// At returns the tile at the passed coordinates, or an error if they are outside the well.
+// Coordinate (0, 0) is the top left cell.
+func (p Well) At(x, y int) (int, error) {
+	if !p.inBounds(x, y) {
+		return Empty, fmt.Errorf(errorOutOfBounds)
+	}
+	return p[x][y], nil
+}
+
+// Set puts the passed tile at the passed coordinates, returning an error if they are outside the well
+// or the tile is not valid. Tiles are not settled, so they can be left floating over empty cells.
+func (p Well) Set(x, y, tile int) error {
+	if !p.inBounds(x, y) {
+		return fmt.Errorf(errorOutOfBounds)
+	}
+	if tile < Remove || tile > MagicJewel {
+		return fmt.Errorf(errorInvalidTile)
+	}
+	p[x][y] = tile
+	return nil
+}
+
+// Place puts the tiles of the passed column in the well at its current position, as if it had landed there,
+// overwriting any tiles in those cells. Tiles above the top of the well are discarded.
+// Returns an error if the bottom tile of the column is outside the well, or any of its tiles is not valid,
+// as in Set. In that case, the well is not modified.
+func (p Well) Place(col Column) error {
+	if !p.inBounds(col.X, col.Y) {
+		return fmt.Errorf(errorOutOfBounds)
+	}
+	for _, tile := range col.Tileset {
+		if tile < Remove || tile > MagicJewel {
+			return fmt.Errorf(errorInvalidTile)
+		}
+	}
+	p.lock(&col)
+	return nil
+}
+
+// ResolveChains removes all lines of minMatch or more tiles of the same color, settling remaining tiles after
+// every removal and repeating until no more lines are found, as a game does after a column lands.
+// If minMatch is 0, DefaultMinMatch is used. Returns every step of the chain, which is empty if no tiles were removed,
+// or an error if minMatch is negative or 1, as in Config.MinMatch.
+func (p Well) ResolveChains(minMatch int) ([]ChainStep, error) {
+	if minMatch < 0 || minMatch == 1 {
+		return nil, fmt.Errorf(errorInvalidMatch)
+	}
+	if minMatch == 0 {
+		minMatch = DefaultMinMatch
+	}
+	var chain []ChainStep
+	for {
+		removed, matches := p.markTilesToRemove(minMatch)
+		if removed == 0 {
+			return chain, nil
+		}
+		chain = append(chain, ChainStep{
+			Matches: matches,
+			Removed: removed,
+			Moves:   p.settle(),
+		})
+	}
+}
+
+// settle moves down all tiles which have empty cells below, returning the moves done
+func (p Well) settle() []Move {
+	var moves []Move
+	for x := 0; x < p.Width(); x++ {
 		moveDown := 0
-		for y := p.height() - 1; y >= 0; y-- {
+		for y := len(p[x]) - 1; y >= 0; y-- {
 			// This cell contains a tile to be removed, do not put it in the slice of tiles to settle again
 			if p[x][y] < 0 {
 				p[x][y] = Empty
@@ -131,9 +291,11 @@ func (p Well) settle() {
 			if moveDown > 0 {
 				p[x][y+moveDown] = p[x][y]
 				p[x][y] = Empty
+				moves = append(moves, Move{From: Position{x, y}, To: Position{x, y + moveDown}, Tile: p[x][y+moveDown]})
 			}
 		}
 	}
+	return moves
 }
 
 // lock put the values of the passed column in the well
@@ -146,9 +308,11 @@ func (p Well) lock(pc *Column) {
 	}
 }
 
-func (p Well) copy() Well {
-	well := NewWell(p.width(), p.height())
+// Clone returns a deep copy of the well, keeping the height of every column
+func (p Well) Clone() Well {
+	well := make(Well, p.Width())
 	for i := range p {
+		well[i] = make([]int, len(p[i]))
 		copy(well[i], p[i])
 	}
 	return well
@@ -0,0 +1,313 @@
+package doric_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/svera/doric"
+)
+
+func TestLandingY(t *testing.T) {
+	well := parseWell(t, `
+		...
+		...
+		...
+		.1.
+		.1.
+	`)
+	tests := []struct {
+		name     string
+		column   doric.Column
+		expected int
+	}{
+		{
+			name:     "Must land on the floor",
+			column:   doric.Column{Tileset: []int{1, 2, 3}, X: 0, Y: 0},
+			expected: 4,
+		},
+		{
+			name:     "Must land on top of other tiles",
+			column:   doric.Column{Tileset: []int{1, 2, 3}, X: 1, Y: 0},
+			expected: 2,
+		},
+		{
+			name:     "Must stay where it is if it cannot fall",
+			column:   doric.Column{Tileset: []int{1, 2, 3}, X: 2, Y: 4},
+			expected: 4,
+		},
+		{
+			name:     "Must stay where it is if it is right of the well",
+			column:   doric.Column{Tileset: []int{1, 2, 3}, X: 3, Y: 0},
+			expected: 0,
+		},
+		{
+			name:     "Must stay where it is if it is below the well",
+			column:   doric.Column{Tileset: []int{1, 2, 3}, X: 0, Y: 7},
+			expected: 7,
+		},
+		{
+			name:     "Must stay where it is if it is above the well",
+			column:   doric.Column{Tileset: []int{1, 2, 3}, X: 0, Y: -1},
+			expected: -1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if y := well.LandingY(test.column); y != test.expected {
+				t.Errorf("Expected landing row %d but got %d", test.expected, y)
+			}
+		})
+	}
+
+	t.Run("Must land on the floor of its own column if columns have different heights", func(t *testing.T) {
+		uneven := doric.Well{{0, 0, 0, 0}, {0, 0}, {0, 0, 0, 0}}
+		if y := uneven.LandingY(doric.Column{Tileset: []int{1, 2, 3}, X: 1, Y: 0}); y != 1 {
+			t.Errorf("Expected landing row %d but got %d", 1, y)
+		}
+	})
+}
+
+func TestWellAtAndSet(t *testing.T) {
+	well := doric.NewWell(3, 4)
+	tests := []struct {
+		name    string
+		x       int
+		y       int
+		tile    int
+		wantErr bool
+	}{
+		{name: "Must set and get a tile inside the well", x: 2, y: 3, tile: 5},
+		{name: "Must accept Remove markers", x: 0, y: 0, tile: doric.Remove},
+		{name: "Must accept magic jewels", x: 1, y: 1, tile: doric.MagicJewel},
+		{name: "Must return error if x is out of bounds", x: 3, y: 0, tile: 1, wantErr: true},
+		{name: "Must return error if y is negative", x: 0, y: -1, tile: 1, wantErr: true},
+		{name: "Must return error if tile is not valid", x: 0, y: 0, tile: doric.MagicJewel + 1, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := well.Set(test.x, test.y, test.tile)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("Expected error setting tile %d at (%d, %d)", test.tile, test.x, test.y)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got %v", err)
+			}
+			if tile, err := well.At(test.x, test.y); err != nil || tile != test.tile {
+				t.Errorf("Expected tile %d but got %d (%v)", test.tile, tile, err)
+			}
+		})
+	}
+
+	if _, err := well.At(-1, 0); err == nil {
+		t.Errorf("Expected error getting a tile out of bounds")
+	}
+
+	uneven := doric.Well{{0, 0}, {0}}
+	if _, err := uneven.At(1, 1); err == nil {
+		t.Errorf("Expected error getting a tile below a shorter column")
+	}
+	if err := uneven.Set(1, 1, 1); err == nil {
+		t.Errorf("Expected error setting a tile below a shorter column")
+	}
+}
+
+func TestWellClone(t *testing.T) {
+	well := doric.NewWell(3, 3)
+	clone := well.Clone()
+	clone.Set(1, 1, 2)
+	if tile, _ := well.At(1, 1); tile != doric.Empty {
+		t.Errorf("Expected original well not to be modified, got tile %d", tile)
+	}
+	if clone.Width() != 3 || clone.Height() != 3 {
+		t.Errorf("Expected clone to be 3x3 but got %dx%d", clone.Width(), clone.Height())
+	}
+
+	uneven := doric.Well{{0, 1}, {0, 0, 0, 1}, {0, 0, 0, 1}}
+	if clone := uneven.Clone(); !reflect.DeepEqual(clone, uneven) {
+		t.Errorf("Expected clone %v of a well with columns of different heights but got %v", uneven, clone)
+	}
+}
+
+func TestWellPlace(t *testing.T) {
+	t.Run("Must put column tiles from the bottom up, discarding the ones above the well", func(t *testing.T) {
+		well := doric.NewWell(3, 3)
+		if err := well.Place(doric.Column{Tileset: []int{1, 2, 3}, X: 1, Y: 1}); err != nil {
+			t.Fatalf("Expected no error but got %v", err)
+		}
+		expected := parseWell(t, `
+			.2.
+			.1.
+			...
+		`)
+		if !reflect.DeepEqual(well, expected) {
+			t.Errorf("Expected well %v but got %v", expected, well)
+		}
+	})
+
+	t.Run("Must return error if column has invalid tiles", func(t *testing.T) {
+		well := doric.NewWell(3, 3)
+		if err := well.Place(doric.Column{Tileset: []int{1, doric.MagicJewel + 1, 3}, X: 1, Y: 2}); err == nil {
+			t.Errorf("Expected error placing a column with invalid tiles")
+		}
+		if !reflect.DeepEqual(well, doric.NewWell(3, 3)) {
+			t.Errorf("Expected well not to be modified, got %v", well)
+		}
+	})
+
+	t.Run("Must return error if column is out of bounds", func(t *testing.T) {
+		well := doric.NewWell(3, 3)
+		if err := well.Place(doric.Column{Tileset: []int{1, 2, 3}, X: 3, Y: 2}); err == nil {
+			t.Errorf("Expected error placing a column out of bounds")
+		}
+	})
+}
+
+func TestWellResolveChains(t *testing.T) {
+	well := parseWell(t, `
+		...3..
+		...2..
+		.22111
+	`)
+	chain, err := well.ResolveChains(0)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	expected := []doric.ChainStep{
+		{
+			Matches: []doric.Match{
+				{Direction: doric.DirectionHorizontal, Length: 3, Color: 1, Cells: []doric.Position{{X: 3, Y: 2}, {X: 4, Y: 2}, {X: 5, Y: 2}}},
+			},
+			Removed: 3,
+			Moves: []doric.Move{
+				{From: doric.Position{X: 3, Y: 1}, To: doric.Position{X: 3, Y: 2}, Tile: 2},
+				{From: doric.Position{X: 3, Y: 0}, To: doric.Position{X: 3, Y: 1}, Tile: 3},
+			},
+		},
+		{
+			Matches: []doric.Match{
+				{Direction: doric.DirectionHorizontal, Length: 3, Color: 2, Cells: []doric.Position{{X: 1, Y: 2}, {X: 2, Y: 2}, {X: 3, Y: 2}}},
+			},
+			Removed: 3,
+			Moves: []doric.Move{
+				{From: doric.Position{X: 3, Y: 1}, To: doric.Position{X: 3, Y: 2}, Tile: 3},
+			},
+		},
+	}
+	if !reflect.DeepEqual(chain, expected) {
+		t.Errorf("Expected chain %v but got %v", expected, chain)
+	}
+	expectedWell := parseWell(t, `
+		......
+		......
+		...3..
+	`)
+	if !reflect.DeepEqual(well, expectedWell) {
+		t.Errorf("Expected well %v but got %v", expectedWell, well)
+	}
+	if chain, _ := well.ResolveChains(0); len(chain) != 0 {
+		t.Errorf("Expected no more steps but got %v", chain)
+	}
+
+	t.Run("Must resolve wells whose columns have different heights", func(t *testing.T) {
+		uneven := doric.Well{{0, 0, 0, 1}, {0, 1}, {0, 0, 0, 1}}
+		chain, _ := uneven.ResolveChains(3)
+		if len(chain) != 0 {
+			t.Errorf("Expected no steps but got %v", chain)
+		}
+		uneven = doric.Well{{0, 1}, {0, 1}, {0, 1, 2}}
+		chain, _ = uneven.ResolveChains(3)
+		if len(chain) != 1 || chain[0].Removed != 3 {
+			t.Errorf("Expected a step removing 3 tiles but got %v", chain)
+		}
+	})
+
+	t.Run("Must return error if minMatch is not valid", func(t *testing.T) {
+		for _, minMatch := range []int{-1, 1} {
+			if _, err := doric.NewWell(3, 3).ResolveChains(minMatch); err == nil {
+				t.Errorf("Expected error resolving chains with minMatch %d", minMatch)
+			}
+		}
+	})
+}
+
+func TestWellValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		well     doric.Well
+		expected error
+	}{
+		{
+			name: "Must accept a valid well",
+			well: parseWell(t, `
+				...
+				.2.
+				132
+			`),
+		},
+		{
+			name:     "Must reject wells narrower than MinWellWidth",
+			well:     doric.NewWell(2, 5),
+			expected: doric.ErrWellTooNarrow,
+		},
+		{
+			name:     "Must reject wells without width",
+			well:     doric.NewWell(0, 5),
+			expected: doric.ErrWellTooNarrow,
+		},
+		{
+			name:     "Must reject wells without height",
+			well:     doric.NewWell(3, 0),
+			expected: doric.ErrWellTooShort,
+		},
+		{
+			name:     "Must reject wells with columns of different heights",
+			well:     doric.Well{{0, 0}, {0, 0, 0}, {0, 0}},
+			expected: doric.ErrWellNotRectangular,
+		},
+		{
+			name:     "Must reject tiles out of range",
+			well:     doric.Well{{0, 0}, {0, 12}, {0, 0}},
+			expected: doric.ErrInvalidTile,
+		},
+		{
+			name: "Must reject magic jewels",
+			well: parseWell(t, `
+				...
+				.*.
+			`),
+			expected: doric.ErrInvalidTile,
+		},
+		{
+			name: "Must reject floating tiles",
+			well: parseWell(t, `
+				.1.
+				.2.
+				1.3
+			`),
+			expected: doric.ErrFloatingTile,
+		},
+		{
+			name: "Must reject Remove markers",
+			well: parseWell(t, `
+				...
+				.x.
+			`),
+			expected: doric.ErrRemoveMarker,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.well.Validate(); !errors.Is(err, test.expected) {
+				t.Errorf("Expected error %v but got %v", test.expected, err)
+			}
+		})
+	}
+}